@@ -0,0 +1,299 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/golangci/golangci-lint/internal/renameio"
+)
+
+// Backend is a shared storage of cache entries, e.g. for a team or CI jobs.
+// The local cache reads through it on misses and writes through it on puts.
+// Like in Bazel's remote cache, action entries map an ActionID to an OutputID
+// and outputs are content-addressed by OutputID.
+type Backend interface {
+	// GetAction returns the output ID of the action or an error satisfying IsErrMissing.
+	GetAction(id ActionID) (OutputID, error)
+	// GetOutput returns the output or an error satisfying IsErrMissing.
+	GetOutput(out OutputID) ([]byte, error)
+	PutAction(id ActionID, out OutputID) error
+	PutOutput(out OutputID, data []byte) error
+}
+
+// backendTimeout is short: a slow or unreachable backend must not slow down the run,
+// it's disabled after the first transport error.
+const backendTimeout = 5 * time.Second
+
+// NewBackend returns the backend by its spec: an http(s) URL of a server
+// supporting GET/PUT of <URL>/ac/<ActionID> and <URL>/cas/<OutputID>
+// or an absolute path of a shared directory (e.g. an NFS mount or a CI cache volume).
+func NewBackend(spec string) (Backend, error) {
+	if strings.HasPrefix(spec, "http://") || strings.HasPrefix(spec, "https://") {
+		return NewHTTPBackend(spec), nil
+	}
+
+	if !filepath.IsAbs(spec) {
+		return nil, fmt.Errorf("cache backend %q is neither an http(s) URL nor an absolute path", spec)
+	}
+	return NewDirBackend(spec), nil
+}
+
+// DirBackend stores entries in a directory shared by machines: unlike Cache,
+// it doesn't rely on file locks and writes files atomically.
+type DirBackend struct {
+	dir string
+}
+
+func NewDirBackend(dir string) *DirBackend {
+	return &DirBackend{dir: dir}
+}
+
+func (b *DirBackend) fileName(kind string, id [HashSize]byte) string {
+	return filepath.Join(b.dir, kind, fmt.Sprintf("%02x", id[0]), fmt.Sprintf("%x", id))
+}
+
+func (b *DirBackend) read(kind string, id [HashSize]byte) ([]byte, error) {
+	data, err := renameio.ReadFile(b.fileName(kind, id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errMissing
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+func (b *DirBackend) write(kind string, id [HashSize]byte, data []byte) error {
+	name := b.fileName(kind, id)
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+	return renameio.WriteFile(name, data, 0666)
+}
+
+func (b *DirBackend) GetAction(id ActionID) (OutputID, error) {
+	data, err := b.read("ac", id)
+	if err != nil {
+		return OutputID{}, err
+	}
+	return parseOutputID(data)
+}
+
+func (b *DirBackend) GetOutput(out OutputID) ([]byte, error) {
+	return b.read("cas", out)
+}
+
+func (b *DirBackend) PutAction(id ActionID, out OutputID) error {
+	return b.write("ac", id, []byte(hex.EncodeToString(out[:])))
+}
+
+func (b *DirBackend) PutOutput(out OutputID, data []byte) error {
+	if _, err := os.Stat(b.fileName("cas", out)); err == nil {
+		return nil // content-addressed: already stored
+	}
+	return b.write("cas", out, data)
+}
+
+// HTTPBackend stores entries on a server by GET/PUT requests
+// to <URL>/ac/<ActionID> and <URL>/cas/<OutputID>. A missing entry is 404.
+// After the first transport error (e.g. a timeout) all requests fail immediately.
+type HTTPBackend struct {
+	url    string
+	client *http.Client
+
+	mu         sync.Mutex
+	disableErr error
+}
+
+func NewHTTPBackend(url string) *HTTPBackend {
+	return &HTTPBackend{
+		url:    strings.TrimSuffix(url, "/"),
+		client: &http.Client{Timeout: backendTimeout},
+	}
+}
+
+func (b *HTTPBackend) entryURL(kind string, id [HashSize]byte) string {
+	return fmt.Sprintf("%s/%s/%x", b.url, kind, id)
+}
+
+// do sends the request unless the backend is disabled by a previous transport error.
+func (b *HTTPBackend) do(req *http.Request) (*http.Response, error) {
+	b.mu.Lock()
+	disableErr := b.disableErr
+	b.mu.Unlock()
+	if disableErr != nil {
+		return nil, errors.Wrap(disableErr, "cache backend is disabled after error")
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		b.mu.Lock()
+		if b.disableErr == nil {
+			b.disableErr = err
+		}
+		b.mu.Unlock()
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (b *HTTPBackend) get(kind string, id [HashSize]byte) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, b.entryURL(kind, id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := b.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return ioutil.ReadAll(resp.Body)
+	case http.StatusNotFound:
+		return nil, errMissing
+	default:
+		return nil, fmt.Errorf("GET %s: %s", b.entryURL(kind, id), resp.Status)
+	}
+}
+
+func (b *HTTPBackend) put(kind string, id [HashSize]byte, data []byte) error {
+	req, err := http.NewRequest(http.MethodPut, b.entryURL(kind, id), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := b.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("PUT %s: %s", b.entryURL(kind, id), resp.Status)
+	}
+	return nil
+}
+
+func (b *HTTPBackend) GetAction(id ActionID) (OutputID, error) {
+	data, err := b.get("ac", id)
+	if err != nil {
+		return OutputID{}, err
+	}
+	return parseOutputID(data)
+}
+
+func (b *HTTPBackend) GetOutput(out OutputID) ([]byte, error) {
+	return b.get("cas", out)
+}
+
+func (b *HTTPBackend) PutAction(id ActionID, out OutputID) error {
+	return b.put("ac", id, []byte(hex.EncodeToString(out[:])))
+}
+
+func (b *HTTPBackend) PutOutput(out OutputID, data []byte) error {
+	return b.put("cas", out, data)
+}
+
+func parseOutputID(data []byte) (OutputID, error) {
+	var out OutputID
+	if n, err := hex.Decode(out[:], bytes.TrimSpace(data)); err != nil || n != HashSize {
+		return OutputID{}, fmt.Errorf("bad output id %q", data)
+	}
+	return out, nil
+}
+
+// getFromBackend reads the action output through the backend and stores it locally.
+func (c *Cache) getFromBackend(id ActionID) (Entry, error) {
+	out, err := c.backend.GetAction(id)
+	if err != nil {
+		return Entry{}, errors.Wrap(err, "failed to get action from cache backend")
+	}
+	data, err := c.backend.GetOutput(out)
+	if err != nil {
+		return Entry{}, errors.Wrap(err, "failed to get output from cache backend")
+	}
+	if sha256.Sum256(data) != out {
+		return Entry{}, errMissing // corrupted or partially written output
+	}
+
+	// Don't use put: it would write the output back to the backend.
+	if err = c.copyFile(bytes.NewReader(data), out, int64(len(data))); err != nil {
+		return Entry{}, errors.Wrap(err, "failed to save output from cache backend")
+	}
+	if err = c.putIndexEntry(id, out, int64(len(data)), false); err != nil {
+		return Entry{}, errors.Wrap(err, "failed to save action from cache backend")
+	}
+	return c.get(id)
+}
+
+// maxBackendPuts limits the number of concurrent asynchronous writes to the backend.
+const maxBackendPuts = 8
+
+// putToBackendAsync writes the action output through the backend in background:
+// the local put doesn't wait for it and doesn't fail on its errors, see FlushBackend.
+func (c *Cache) putToBackendAsync(id ActionID, out OutputID, file io.ReadSeeker) {
+	if _, err := file.Seek(0, 0); err != nil {
+		c.setBackendErr(err)
+		return
+	}
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		c.setBackendErr(err)
+		return
+	}
+
+	c.backendPuts.Add(1)
+	go func() {
+		defer c.backendPuts.Done()
+
+		c.backendSem <- struct{}{}
+		defer func() {
+			<-c.backendSem
+		}()
+
+		if err := c.backend.PutOutput(out, data); err != nil {
+			c.setBackendErr(errors.Wrap(err, "failed to put output to cache backend"))
+			return
+		}
+		if err := c.backend.PutAction(id, out); err != nil {
+			c.setBackendErr(errors.Wrap(err, "failed to put action to cache backend"))
+		}
+	}()
+}
+
+// setBackendErr saves the first error of the backend: the backend is best-effort,
+// its errors don't fail cache operations.
+func (c *Cache) setBackendErr(err error) {
+	c.backendMu.Lock()
+	defer c.backendMu.Unlock()
+
+	if c.backendErr == nil {
+		c.backendErr = err
+	}
+}
+
+// FlushBackend waits for asynchronous writes to the backend and returns
+// the first error of the backend, if any.
+func (c *Cache) FlushBackend() error {
+	c.backendPuts.Wait()
+
+	c.backendMu.Lock()
+	defer c.backendMu.Unlock()
+	return c.backendErr
+}
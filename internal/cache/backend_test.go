@@ -0,0 +1,194 @@
+package cache
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDirBackend(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "cachetest-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	testBackend(t, NewDirBackend(dir))
+}
+
+func TestHTTPBackend(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	entries := map[string][]byte{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch r.Method {
+		case http.MethodGet:
+			data, ok := entries[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write(data)
+		case http.MethodPut:
+			data, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			entries[r.URL.Path] = data
+		}
+	}))
+	defer srv.Close()
+
+	testBackend(t, NewHTTPBackend(srv.URL+"/"))
+
+	for path := range entries {
+		if !strings.HasPrefix(path, "/ac/") && !strings.HasPrefix(path, "/cas/") {
+			t.Errorf("unexpected entry path %s", path)
+		}
+	}
+}
+
+// testBackend checks that an entry put by one cache is read through the backend by another one.
+func testBackend(t *testing.T, backend Backend) {
+	var dirs []string
+	defer func() {
+		for _, dir := range dirs {
+			os.RemoveAll(dir)
+		}
+	}()
+
+	openCache := func() *Cache {
+		dir, err := ioutil.TempDir("", "cachetest-")
+		if err != nil {
+			t.Fatal(err)
+		}
+		dirs = append(dirs, dir)
+
+		c, err := Open(dir)
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		c.SetBackend(backend)
+		return c
+	}
+
+	c1, c2 := openCache(), openCache()
+	if _, _, err := c2.GetBytes(dummyID(1)); !IsErrMissing(err) {
+		t.Fatalf("GetBytes(1) before put: %v, want missing", err)
+	}
+
+	if err := c1.PutBytes(dummyID(1), []byte("data")); err != nil {
+		t.Fatalf("PutBytes(1): %v", err)
+	}
+	if err := c1.FlushBackend(); err != nil {
+		t.Fatalf("FlushBackend: %v", err)
+	}
+
+	data, _, err := c2.GetBytes(dummyID(1))
+	if err != nil || string(data) != "data" {
+		t.Fatalf("GetBytes(1) = %q, %v, want %q, nil", data, err, "data")
+	}
+
+	// The entry was saved into the local cache.
+	c2.SetBackend(nil)
+	data, _, err = c2.GetBytes(dummyID(1))
+	if err != nil || string(data) != "data" {
+		t.Fatalf("local GetBytes(1) = %q, %v, want %q, nil", data, err, "data")
+	}
+}
+
+func TestHTTPBackendSlowServer(t *testing.T) {
+	t.Parallel()
+
+	var requests int32
+	unblock := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		<-unblock
+	}))
+	defer srv.Close()
+	defer close(unblock)
+
+	backend := NewHTTPBackend(srv.URL)
+	backend.client.Timeout = 100 * time.Millisecond
+
+	dir, err := ioutil.TempDir("", "cachetest-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	c.SetBackend(backend)
+
+	startedAt := time.Now()
+	for i := 0; i < 10; i++ {
+		if _, _, err = c.GetBytes(dummyID(i)); !IsErrMissing(err) {
+			t.Fatalf("GetBytes(%d) = %v, want missing", i, err)
+		}
+	}
+
+	// The local put succeeds, the failing remote put doesn't fail it.
+	if err = c.PutBytes(dummyID(1), []byte("data")); err != nil {
+		t.Fatalf("PutBytes(1): %v", err)
+	}
+	data, _, err := c.GetBytes(dummyID(1))
+	if err != nil || string(data) != "data" {
+		t.Fatalf("GetBytes(1) = %q, %v, want %q, nil", data, err, "data")
+	}
+
+	if err = c.FlushBackend(); err == nil {
+		t.Fatal("FlushBackend: want the timeout error")
+	}
+	if elapsed := time.Since(startedAt); elapsed > 5*time.Second {
+		t.Fatalf("the slow backend took %s", elapsed)
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Fatalf("backend got %d requests, want 1: it must be disabled after the timeout", n)
+	}
+}
+
+func TestHTTPBackendUnreachable(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	dir, err := ioutil.TempDir("", "cachetest-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	c.SetBackend(NewHTTPBackend(srv.URL))
+
+	if _, _, err = c.GetBytes(dummyID(1)); !IsErrMissing(err) {
+		t.Fatalf("GetBytes(1) = %v, want missing", err)
+	}
+	if err = c.PutBytes(dummyID(1), []byte("data")); err != nil {
+		t.Fatalf("PutBytes(1): %v", err)
+	}
+	if err = c.FlushBackend(); err == nil {
+		t.Fatal("FlushBackend: want the connection error")
+	}
+}
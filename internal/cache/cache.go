@@ -16,8 +16,10 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -36,8 +38,15 @@ type OutputID [HashSize]byte
 
 // A Cache is a package cache, backed by a file system directory tree.
 type Cache struct {
-	dir string
-	now func() time.Time
+	dir     string
+	now     func() time.Time
+	backend Backend // nil if there is only the local directory
+	limits  TrimLimits
+
+	backendPuts sync.WaitGroup
+	backendSem  chan struct{}
+	backendMu   sync.Mutex
+	backendErr  error // the first error of the backend, see FlushBackend
 }
 
 // Open opens and returns the cache in the given directory.
@@ -67,12 +76,18 @@ func Open(dir string) (*Cache, error) {
 		}
 	}
 	c := &Cache{
-		dir: dir,
-		now: time.Now,
+		dir:        dir,
+		now:        time.Now,
+		backendSem: make(chan struct{}, maxBackendPuts),
 	}
 	return c, nil
 }
 
+// SetBackend makes the cache read through and write through the shared backend.
+func (c *Cache) SetBackend(backend Backend) {
+	c.backend = backend
+}
+
 // fileName returns the name of the file corresponding to the given id.
 func (c *Cache) fileName(id [HashSize]byte, key string) string {
 	return filepath.Join(c.dir, fmt.Sprintf("%02x", id[0]), fmt.Sprintf("%x", id)+"-"+key)
@@ -88,6 +103,10 @@ const (
 	// action entry file is "v1 <hex id> <hex out> <decimal size space-padded to 20 bytes> <unixnano space-padded to 20 bytes>\n"
 	hexSize   = HashSize * 2
 	entrySize = 2 + 1 + hexSize + 1 + hexSize + 1 + 20 + 1 + 20 + 1
+
+	// action entry file can be followed by "k <kind>\n", see PutBytesKind
+	maxKindSize = 64
+	kindSize    = 2 + maxKindSize + 1
 )
 
 // verify controls whether to run the cache in verify mode.
@@ -131,17 +150,42 @@ func (c *Cache) Get(id ActionID) (Entry, error) {
 	if verify {
 		return Entry{}, errMissing
 	}
-	return c.get(id)
+
+	entry, err := c.get(id)
+	if IsErrMissing(err) && c.backend != nil {
+		entry, err = c.getFromBackend(id)
+		if err != nil && !IsErrMissing(err) {
+			// The backend is best-effort: it's a local miss.
+			c.setBackendErr(err)
+			return Entry{}, errMissing
+		}
+	}
+	return entry, err
 }
 
 type Entry struct {
 	OutputID OutputID
 	Size     int64
+	Kind     string // e.g. "facts", empty if unknown
 	Time     time.Time
 }
 
 // get is Get but does not respect verify mode, so that Put can use it.
 func (c *Cache) get(id ActionID) (Entry, error) {
+	entry, err := c.readEntry(id)
+	if err != nil {
+		return entry, err
+	}
+
+	fileName := c.fileName(id, "a")
+	if err = c.used(fileName); err != nil {
+		return Entry{}, errors.Wrapf(err, "failed to mark %s as used", fileName)
+	}
+	return entry, nil
+}
+
+// readEntry is get but does not mark the entry as used.
+func (c *Cache) readEntry(id ActionID) (Entry, error) {
 	missing := func() (Entry, error) {
 		return Entry{}, errMissing
 	}
@@ -157,10 +201,24 @@ func (c *Cache) get(id ActionID) (Entry, error) {
 		return failed(err)
 	}
 	defer f.Close()
-	entry := make([]byte, entrySize+1) // +1 to detect whether f is too long
-	if n, readErr := io.ReadFull(f, entry); n != entrySize || readErr != io.ErrUnexpectedEOF {
+	return parseEntryData(id, f, fileName)
+}
+
+// parseEntryData parses the action entry read from r, fileName is used in errors.
+func parseEntryData(id ActionID, r io.Reader, fileName string) (Entry, error) {
+	failed := func(err error) (Entry, error) {
+		return Entry{}, err
+	}
+	entry := make([]byte, entrySize+kindSize+1) // +1 to detect whether f is too long
+	n, readErr := io.ReadFull(r, entry)
+	if n < entrySize || n > entrySize+kindSize || readErr != io.ErrUnexpectedEOF {
 		return failed(fmt.Errorf("read %d/%d bytes from %s with error %s", n, entrySize, fileName, readErr))
 	}
+	kind, err := parseKind(entry[entrySize:n])
+	if err != nil {
+		return failed(errors.Wrapf(err, "bad kind in %s", fileName))
+	}
+	entry = entry[:entrySize]
 	if entry[0] != 'v' || entry[1] != '1' || entry[2] != ' ' || entry[3+hexSize] != ' ' || entry[3+hexSize+1+hexSize] != ' ' || entry[3+hexSize+1+hexSize+1+20] != ' ' || entry[entrySize-1] != '\n' {
 		return failed(fmt.Errorf("bad data in %s", fileName))
 	}
@@ -169,9 +227,12 @@ func (c *Cache) get(id ActionID) (Entry, error) {
 	esize, entry := entry[1:1+20], entry[1+20:]
 	etime := entry[1 : 1+20]
 	var buf [HashSize]byte
-	if _, err = hex.Decode(buf[:], eid); err != nil || buf != id {
+	if _, err = hex.Decode(buf[:], eid); err != nil {
 		return failed(errors.Wrapf(err, "failed to hex decode eid data in %s", fileName))
 	}
+	if buf != id {
+		return failed(fmt.Errorf("entry id %x in %s doesn't match %x", buf, fileName, id))
+	}
 	if _, err = hex.Decode(buf[:], eout); err != nil {
 		return failed(errors.Wrapf(err, "failed to hex decode eout data in %s", fileName))
 	}
@@ -192,11 +253,17 @@ func (c *Cache) get(id ActionID) (Entry, error) {
 		return failed(fmt.Errorf("failed to parse etime int from %s with error %s", fileName, err))
 	}
 
-	if err = c.used(fileName); err != nil {
-		return failed(errors.Wrapf(err, "failed to mark %s as used", fileName))
-	}
+	return Entry{buf, size, kind, time.Unix(0, tm)}, nil
+}
 
-	return Entry{buf, size, time.Unix(0, tm)}, nil
+func parseKind(line []byte) (string, error) {
+	if len(line) == 0 {
+		return "", nil
+	}
+	if len(line) < 3 || line[0] != 'k' || line[1] != ' ' || line[len(line)-1] != '\n' {
+		return "", fmt.Errorf("invalid kind line %q", line)
+	}
+	return string(line[2 : len(line)-1]), nil
 }
 
 // GetBytes looks up the action ID in the cache and returns
@@ -248,6 +315,9 @@ const (
 	mtimeInterval = 1 * time.Hour
 	trimInterval  = 24 * time.Hour
 	trimLimit     = 5 * 24 * time.Hour
+
+	// sizeTrimInterval is the trimInterval for a cache with a size limit.
+	sizeTrimInterval = 1 * time.Hour
 )
 
 // used makes a best-effort attempt to update mtime on file,
@@ -279,6 +349,26 @@ func (c *Cache) used(file string) error {
 	return nil
 }
 
+// TrimLimits bounds the cache: entries unused for longer than MaxAge are removed,
+// then least recently used entries are removed until the cache fits into MaxSize.
+// Use times are approximate: see mtimeInterval.
+type TrimLimits struct {
+	MaxSize int64         // bytes, 0 means no limit
+	MaxAge  time.Duration // 0 means the default trimLimit
+}
+
+// SetTrimLimits sets limits enforced by Trim and TrimNow.
+func (c *Cache) SetTrimLimits(limits TrimLimits) {
+	c.limits = limits
+}
+
+// TrimStats describes the result of a trim.
+type TrimStats struct {
+	RemovedFiles int
+	RemovedBytes int64
+	Size         int64 // size of entries left
+}
+
 // Trim removes old cache entries that are likely not to be reused.
 func (c *Cache) Trim() {
 	now := c.now()
@@ -286,28 +376,82 @@ func (c *Cache) Trim() {
 	// We maintain in dir/trim.txt the time of the last completed cache trim.
 	// If the cache has been trimmed recently enough, do nothing.
 	// This is the common case.
+	// A cache with a size limit is checked more often: it can grow fast.
+	interval := trimInterval
+	if c.limits.MaxSize != 0 {
+		interval = sizeTrimInterval
+	}
 	data, _ := renameio.ReadFile(filepath.Join(c.dir, "trim.txt"))
 	t, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
-	if err == nil && now.Sub(time.Unix(t, 0)) < trimInterval {
+	if err == nil && now.Sub(time.Unix(t, 0)) < interval {
 		return
 	}
 
-	// Trim each of the 256 subdirectories.
+	c.TrimNow()
+}
+
+// TrimNow removes entries exceeding the limits regardless of the time of the last trim.
+func (c *Cache) TrimNow() TrimStats {
+	now := c.now()
+
+	maxAge := c.limits.MaxAge
+	if maxAge == 0 {
+		maxAge = trimLimit
+	}
+
 	// We subtract an additional mtimeInterval
 	// to account for the imprecision of our "last used" mtimes.
-	cutoff := now.Add(-trimLimit - mtimeInterval)
+	cutoff := now.Add(-maxAge - mtimeInterval)
+
+	var stats TrimStats
+	var files []cacheFile
 	for i := 0; i < 256; i++ {
 		subdir := filepath.Join(c.dir, fmt.Sprintf("%02x", i))
-		c.trimSubdir(subdir, cutoff)
+		for _, f := range listCacheFiles(subdir) {
+			if f.modTime.Before(cutoff) {
+				stats.remove(f)
+				continue
+			}
+			files = append(files, f)
+			stats.Size += f.size
+		}
+	}
+
+	if c.limits.MaxSize != 0 && stats.Size > c.limits.MaxSize {
+		// Remove least recently used entries first.
+		sort.Slice(files, func(i, j int) bool {
+			return files[i].modTime.Before(files[j].modTime)
+		})
+		for _, f := range files {
+			if stats.Size <= c.limits.MaxSize {
+				break
+			}
+			stats.remove(f)
+			stats.Size -= f.size
+		}
 	}
 
 	// Ignore errors from here: if we don't write the complete timestamp, the
 	// cache will appear older than it is, and we'll trim it again next time.
 	_ = renameio.WriteFile(filepath.Join(c.dir, "trim.txt"), []byte(fmt.Sprintf("%d", now.Unix())), 0666)
+	return stats
 }
 
-// trimSubdir trims a single cache subdirectory.
-func (c *Cache) trimSubdir(subdir string, cutoff time.Time) {
+type cacheFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+func (s *TrimStats) remove(f cacheFile) {
+	if os.Remove(f.path) == nil {
+		s.RemovedFiles++
+		s.RemovedBytes += f.size
+	}
+}
+
+// listCacheFiles returns cache entries (xxxx-a and xxxx-d) of a single cache subdirectory.
+func listCacheFiles(subdir string) []cacheFile {
 	// Read all directory entries from subdir before removing
 	// any files, in case removing files invalidates the file offset
 	// in the directory scan. Also, ignore error from f.Readdirnames,
@@ -315,27 +459,73 @@ func (c *Cache) trimSubdir(subdir string, cutoff time.Time) {
 	// want to process any entries found before the error.
 	f, err := os.Open(subdir)
 	if err != nil {
-		return
+		return nil
 	}
 	names, _ := f.Readdirnames(-1)
 	f.Close()
 
+	var files []cacheFile
 	for _, name := range names {
-		// Remove only cache entries (xxxx-a and xxxx-d).
 		if !strings.HasSuffix(name, "-a") && !strings.HasSuffix(name, "-d") {
 			continue
 		}
 		entry := filepath.Join(subdir, name)
 		info, err := os.Stat(entry)
-		if err == nil && info.ModTime().Before(cutoff) {
-			os.Remove(entry)
+		if err != nil {
+			continue
+		}
+		files = append(files, cacheFile{path: entry, size: info.Size(), modTime: info.ModTime()})
+	}
+	return files
+}
+
+// Stats returns the number of action entries by their kinds, see PutBytesKind.
+// Entries of unknown kind are counted by the empty kind.
+func (c *Cache) Stats() (map[string]int, error) {
+	ret := map[string]int{}
+	for i := 0; i < 256; i++ {
+		subdir := filepath.Join(c.dir, fmt.Sprintf("%02x", i))
+		for _, f := range listCacheFiles(subdir) {
+			if !strings.HasSuffix(f.path, "-a") {
+				continue
+			}
+
+			kind, err := readEntryKind(f.path)
+			if err != nil {
+				return nil, err
+			}
+			ret[kind]++
 		}
 	}
+	return ret, nil
+}
+
+func readEntryKind(path string) (string, error) {
+	data, err := robustio.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) { // removed concurrently
+			return "", nil
+		}
+		return "", err
+	}
+	if len(data) < entrySize {
+		return "", nil
+	}
+	kind, err := parseKind(data[entrySize:])
+	if err != nil {
+		return "", nil // corrupted entries are ignored by get
+	}
+	return kind, nil
 }
 
 // putIndexEntry adds an entry to the cache recording that executing the action
 // with the given id produces an output with the given output id (hash) and size.
 func (c *Cache) putIndexEntry(id ActionID, out OutputID, size int64, allowVerify bool) error {
+	return c.putKindIndexEntry(id, out, size, "", allowVerify)
+}
+
+// putKindIndexEntry is putIndexEntry saving also the kind of the entry.
+func (c *Cache) putKindIndexEntry(id ActionID, out OutputID, size int64, kind string, allowVerify bool) error {
 	// Note: We expect that for one reason or another it may happen
 	// that repeating an action produces a different output hash
 	// (for example, if the output contains a time stamp or temp dir name).
@@ -348,6 +538,12 @@ func (c *Cache) putIndexEntry(id ActionID, out OutputID, size int64, allowVerify
 	// are entirely reproducible. As just noted, this may be unrealistic
 	// in some cases but the check is also useful for shaking out real bugs.
 	entry := fmt.Sprintf("v1 %x %x %20d %20d\n", id, out, size, time.Now().UnixNano())
+	if kind != "" {
+		if len(kind) > maxKindSize || strings.Contains(kind, "\n") {
+			return fmt.Errorf("invalid cache entry kind %q", kind)
+		}
+		entry += fmt.Sprintf("k %s\n", kind)
+	}
 
 	if verify && allowVerify {
 		old, err := c.get(id)
@@ -395,7 +591,7 @@ func (c *Cache) putIndexEntry(id ActionID, out OutputID, size int64, allowVerify
 // Put stores the given output in the cache as the output for the action ID.
 // It may read file twice. The content of file must not change between the two passes.
 func (c *Cache) Put(id ActionID, file io.ReadSeeker) (OutputID, int64, error) {
-	return c.put(id, file, true)
+	return c.put(id, file, "", true)
 }
 
 // PutNoVerify is like Put but disables the verify check
@@ -403,10 +599,10 @@ func (c *Cache) Put(id ActionID, file io.ReadSeeker) (OutputID, int64, error) {
 // It is meant for data that is OK to cache but that we expect to vary slightly from run to run,
 // like test output containing times and the like.
 func (c *Cache) PutNoVerify(id ActionID, file io.ReadSeeker) (OutputID, int64, error) {
-	return c.put(id, file, false)
+	return c.put(id, file, "", false)
 }
 
-func (c *Cache) put(id ActionID, file io.ReadSeeker, allowVerify bool) (OutputID, int64, error) {
+func (c *Cache) put(id ActionID, file io.ReadSeeker, kind string, allowVerify bool) (OutputID, int64, error) {
 	// Compute output ID.
 	h := sha256.New()
 	if _, err := file.Seek(0, 0); err != nil {
@@ -425,7 +621,14 @@ func (c *Cache) put(id ActionID, file io.ReadSeeker, allowVerify bool) (OutputID
 	}
 
 	// Add to cache index.
-	return out, size, c.putIndexEntry(id, out, size, allowVerify)
+	if err := c.putKindIndexEntry(id, out, size, kind, allowVerify); err != nil {
+		return out, size, err
+	}
+
+	if c.backend != nil {
+		c.putToBackendAsync(id, out, file)
+	}
+	return out, size, nil
 }
 
 // PutBytes stores the given bytes in the cache as the output for the action ID.
@@ -434,6 +637,12 @@ func (c *Cache) PutBytes(id ActionID, data []byte) error {
 	return err
 }
 
+// PutBytesKind is PutBytes saving also the kind of the entry (e.g. "facts") for Stats.
+func (c *Cache) PutBytesKind(id ActionID, kind string, data []byte) error {
+	_, _, err := c.put(id, bytes.NewReader(data), kind, true)
+	return err
+}
+
 // copyFile copies file into the cache, expecting it to have the given
 // output ID and size, if that file is not present already.
 func (c *Cache) copyFile(file io.ReadSeeker, out OutputID, size int64) error {
@@ -274,3 +274,98 @@ func TestCacheTrim(t *testing.T) {
 		t.Fatal("Trim did not remove dummyID(1)")
 	}
 }
+
+func TestCacheTrimLimits(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "cachetest-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	const start = 1000000000
+	now := int64(start)
+	c.now = func() time.Time { return time.Unix(now, 0) }
+
+	// Each entry is an action file and a 1000 bytes output file.
+	for i := 1; i <= 3; i++ {
+		if err := c.PutBytes(dummyID(i), bytes.Repeat([]byte{byte(i)}, 1000)); err != nil {
+			t.Fatal(err)
+		}
+		now += 2 * int64(mtimeInterval/time.Second)
+	}
+
+	// dummyID(1) is the least recently used entry.
+	c.SetTrimLimits(TrimLimits{MaxSize: 2500})
+	stats := c.TrimNow()
+	if stats.RemovedFiles != 2 || stats.Size > 2500 {
+		t.Fatalf("TrimNow() = %+v, want 2 removed files and size <= 2500", stats)
+	}
+	if _, _, err := c.GetBytes(dummyID(1)); err == nil {
+		t.Fatal("TrimNow did not remove dummyID(1)")
+	}
+	for i := 2; i <= 3; i++ {
+		if _, _, err := c.GetBytes(dummyID(i)); err != nil {
+			t.Fatalf("GetBytes(%d): %v", i, err)
+		}
+	}
+
+	// dummyID(2) isn't used for longer than the max age.
+	c.SetTrimLimits(TrimLimits{MaxAge: time.Hour})
+	now += 3 * int64(mtimeInterval/time.Second)
+	if _, _, err := c.GetBytes(dummyID(3)); err != nil {
+		t.Fatal(err)
+	}
+	c.TrimNow()
+	if _, _, err := c.GetBytes(dummyID(2)); err == nil {
+		t.Fatal("TrimNow did not remove dummyID(2)")
+	}
+	if _, _, err := c.GetBytes(dummyID(3)); err != nil {
+		t.Fatalf("GetBytes(3): %v", err)
+	}
+}
+
+func TestCacheKind(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "cachetest-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	if err := c.PutBytesKind(dummyID(1), "facts", []byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.PutBytesKind(dummyID(2), "facts", []byte("b")); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.PutBytes(dummyID(3), []byte("c")); err != nil {
+		t.Fatal(err)
+	}
+
+	if entry, err := c.Get(dummyID(1)); err != nil || entry.Kind != "facts" {
+		t.Fatalf("Get(1) = %+v, %v, want kind facts", entry, err)
+	}
+	if data, _, err := c.GetBytes(dummyID(3)); err != nil || string(data) != "c" {
+		t.Fatalf("GetBytes(3) = %q, %v, want %q", data, err, "c")
+	}
+
+	stats, err := c.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats["facts"] != 2 || stats[""] != 1 || len(stats) != 2 {
+		t.Fatalf("Stats() = %v, want 2 facts and 1 entry of unknown kind", stats)
+	}
+}
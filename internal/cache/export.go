@@ -0,0 +1,215 @@
+package cache
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/golangci/golangci-lint/internal/renameio"
+	"github.com/golangci/golangci-lint/internal/robustio"
+)
+
+// entryNameRe matches names of cache files in tar streams: the same as in the cache directory.
+var entryNameRe = regexp.MustCompile(`^([0-9a-f]{2})/([0-9a-f]{64})-([ad])$`)
+
+// Export writes action entries with the IDs and their outputs into the tar stream.
+// Missing entries are skipped. It returns the number of exported entries.
+func (c *Cache) Export(w io.Writer, ids []ActionID) (int, error) {
+	tw := tar.NewWriter(w)
+
+	exported := 0
+	writtenOutputs := map[OutputID]bool{}
+	for _, id := range ids {
+		entry, err := c.readEntry(id)
+		if err != nil {
+			if IsErrMissing(err) {
+				continue
+			}
+			return exported, err
+		}
+
+		data, err := robustio.ReadFile(c.fileName(entry.OutputID, "d"))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue // trimmed
+			}
+			return exported, err
+		}
+		if sha256.Sum256(data) != entry.OutputID {
+			continue // corrupted, see Verify
+		}
+
+		if !writtenOutputs[entry.OutputID] {
+			if err = writeTarFile(tw, entry.OutputID, "d", data); err != nil {
+				return exported, err
+			}
+			writtenOutputs[entry.OutputID] = true
+		}
+
+		indexData, err := robustio.ReadFile(c.fileName(id, "a"))
+		if err != nil {
+			return exported, err
+		}
+		if err = writeTarFile(tw, id, "a", indexData); err != nil {
+			return exported, err
+		}
+		exported++
+	}
+
+	if err := tw.Close(); err != nil {
+		return exported, errors.Wrap(err, "failed to finish tar")
+	}
+	return exported, nil
+}
+
+func writeTarFile(tw *tar.Writer, id [HashSize]byte, key string, data []byte) error {
+	hdr := &tar.Header{
+		Name: fmt.Sprintf("%02x/%x-%s", id[0], id, key),
+		Mode: 0666,
+		Size: int64(len(data)),
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return errors.Wrapf(err, "failed to write tar header for %s", hdr.Name)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return errors.Wrapf(err, "failed to write %s to tar", hdr.Name)
+	}
+	return nil
+}
+
+// Import restores entries from the tar stream written by Export.
+// Outputs not matching their hashes are skipped. It returns the number of imported entries.
+func (c *Cache) Import(r io.Reader) (int, error) {
+	tr := tar.NewReader(r)
+
+	imported := 0
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return imported, nil
+		}
+		if err != nil {
+			return imported, errors.Wrap(err, "failed to read tar")
+		}
+
+		m := entryNameRe.FindStringSubmatch(hdr.Name)
+		if m == nil || !strings.HasPrefix(m[2], m[1]) || hdr.Typeflag != tar.TypeReg {
+			return imported, fmt.Errorf("unexpected file %s in tar", hdr.Name)
+		}
+
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return imported, errors.Wrapf(err, "failed to read %s from tar", hdr.Name)
+		}
+
+		if m[3] == "d" {
+			sum := sha256.Sum256(data)
+			if hex.EncodeToString(sum[:]) != m[2] {
+				continue // corrupted output: its entries will be missing
+			}
+		} else {
+			if _, err = parseEntry(m[2], data); err != nil {
+				continue
+			}
+			imported++
+		}
+
+		name := filepath.Join(c.dir, filepath.FromSlash(hdr.Name))
+		if err = renameio.WriteFile(name, data, 0666); err != nil {
+			return imported, errors.Wrapf(err, "failed to write %s", name)
+		}
+		if err = os.Chtimes(name, c.now(), c.now()); err != nil {
+			return imported, errors.Wrapf(err, "failed to change time of file %s", name)
+		}
+	}
+}
+
+// VerifyStats describes the result of Verify.
+type VerifyStats struct {
+	CheckedEntries int
+	RemovedEntries int
+	RemovedOutputs int
+}
+
+// Verify removes corrupted or truncated outputs and action entries
+// with bad data or with missing or corrupted outputs.
+func (c *Cache) Verify() (VerifyStats, error) {
+	var stats VerifyStats
+
+	// Check outputs first: entries are checked by their outputs.
+	var entries []cacheFile
+	for i := 0; i < 256; i++ {
+		for _, f := range listCacheFiles(filepath.Join(c.dir, fmt.Sprintf("%02x", i))) {
+			if strings.HasSuffix(f.path, "-a") {
+				entries = append(entries, f)
+				continue
+			}
+
+			ok, err := isValidOutput(f.path)
+			if err != nil {
+				return stats, err
+			}
+			if !ok && os.Remove(f.path) == nil {
+				stats.RemovedOutputs++
+			}
+		}
+	}
+
+	for _, f := range entries {
+		stats.CheckedEntries++
+
+		data, err := robustio.ReadFile(f.path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return stats, err
+		}
+
+		valid := false
+		if entry, parseErr := parseEntry(strings.TrimSuffix(filepath.Base(f.path), "-a"), data); parseErr == nil {
+			info, statErr := os.Stat(c.fileName(entry.OutputID, "d"))
+			valid = statErr == nil && info.Size() == entry.Size
+		}
+		if !valid && os.Remove(f.path) == nil {
+			stats.RemovedEntries++
+		}
+	}
+
+	return stats, nil
+}
+
+// isValidOutput checks the output content matches its hash in the file name.
+func isValidOutput(path string) (bool, error) {
+	data, err := robustio.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return true, nil // removed concurrently
+		}
+		return false, err
+	}
+
+	sum := sha256.Sum256(data)
+	return strings.HasPrefix(filepath.Base(path), hex.EncodeToString(sum[:])+"-"), nil
+}
+
+// parseEntry parses data of the action entry file named by the hex ID:
+// an entry with another ID inside is invalid, see readEntry.
+func parseEntry(hexID string, data []byte) (Entry, error) {
+	var id ActionID
+	if n, err := hex.Decode(id[:], []byte(hexID)); err != nil || n != len(id) {
+		return Entry{}, fmt.Errorf("bad entry name %s", hexID)
+	}
+
+	return parseEntryData(id, bytes.NewReader(data), hexID)
+}
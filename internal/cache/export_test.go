@@ -0,0 +1,169 @@
+package cache
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExportImport(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "cachetest-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	openCache := func(name string) *Cache {
+		cdir := filepath.Join(dir, name)
+		if err := os.Mkdir(cdir, 0744); err != nil {
+			t.Fatal(err)
+		}
+		c, err := Open(cdir)
+		if err != nil {
+			t.Fatalf("Open(%s): %v", name, err)
+		}
+		return c
+	}
+
+	c1 := openCache("c1")
+	for i := 1; i <= 3; i++ {
+		if err := c1.PutBytesKind(dummyID(i), "facts", []byte{byte(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	exported, err := c1.Export(&buf, []ActionID{dummyID(1), dummyID(2), dummyID(4)})
+	if err != nil || exported != 2 {
+		t.Fatalf("Export() = %d, %v, want 2, nil", exported, err)
+	}
+
+	c2 := openCache("c2")
+	imported, err := c2.Import(&buf)
+	if err != nil || imported != 2 {
+		t.Fatalf("Import() = %d, %v, want 2, nil", imported, err)
+	}
+
+	for i := 1; i <= 2; i++ {
+		data, entry, err := c2.GetBytes(dummyID(i))
+		if err != nil || !bytes.Equal(data, []byte{byte(i)}) || entry.Kind != "facts" {
+			t.Fatalf("GetBytes(%d) = %v, %+v, %v, want %v of kind facts", i, data, entry, err, []byte{byte(i)})
+		}
+	}
+	if _, _, err := c2.GetBytes(dummyID(3)); !IsErrMissing(err) {
+		t.Fatalf("GetBytes(3) error = %v, want missing", err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "cachetest-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	for i := 1; i <= 3; i++ {
+		if err := c.PutBytes(dummyID(i), []byte{byte(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Corrupt the output of dummyID(1) and truncate the entry of dummyID(2).
+	entry, err := c.Get(dummyID(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(c.fileName(entry.OutputID, "d"), []byte("corrupted"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(c.fileName(dummyID(2), "a"), 10); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := c.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := VerifyStats{CheckedEntries: 3, RemovedEntries: 2, RemovedOutputs: 1}
+	if stats != want {
+		t.Fatalf("Verify() = %+v, want %+v", stats, want)
+	}
+
+	if _, err := os.Stat(c.fileName(dummyID(1), "a")); !os.IsNotExist(err) {
+		t.Fatalf("entry of dummyID(1) with corrupted output wasn't removed: %v", err)
+	}
+	if data, _, err := c.GetBytes(dummyID(3)); err != nil || !bytes.Equal(data, []byte{3}) {
+		t.Fatalf("GetBytes(3) = %v, %v, want %v", data, err, []byte{3})
+	}
+}
+
+func TestImportEntryIDMismatch(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "cachetest-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c1, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if err = c1.PutBytes(dummyID(1), []byte{1}); err != nil {
+		t.Fatal(err)
+	}
+	entry, err := c1.Get(dummyID(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := ioutil.ReadFile(c1.fileName(entry.OutputID, "d"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	index, err := ioutil.ReadFile(c1.fileName(dummyID(1), "a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The entry of dummyID(1) is stored under the name of dummyID(2).
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err = writeTarFile(tw, entry.OutputID, "d", output); err != nil {
+		t.Fatal(err)
+	}
+	if err = writeTarFile(tw, dummyID(2), "a", index); err != nil {
+		t.Fatal(err)
+	}
+	if err = tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	c2dir := filepath.Join(dir, "imported")
+	if err = os.Mkdir(c2dir, 0744); err != nil {
+		t.Fatal(err)
+	}
+	c2, err := Open(c2dir)
+	if err != nil {
+		t.Fatalf("Open(c2): %v", err)
+	}
+	imported, err := c2.Import(&buf)
+	if err != nil || imported != 0 {
+		t.Fatalf("Import() = %d, %v, want 0, nil", imported, err)
+	}
+	if _, err := os.Stat(c2.fileName(dummyID(2), "a")); !os.IsNotExist(err) {
+		t.Fatalf("entry with mismatched id was imported: %v", err)
+	}
+}
@@ -4,11 +4,18 @@ import (
 	"bytes"
 	"encoding/gob"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"runtime"
 	"sort"
+	"strings"
 	"sync"
+	"unicode/utf8"
 
+	"github.com/gofrs/flock"
 	"github.com/pkg/errors"
 	"golang.org/x/tools/go/packages"
 
@@ -25,17 +32,43 @@ const (
 	HashModeNeedAllDeps
 )
 
+// Kind is a kind of cached data, see Cache.Stats.
+type Kind string
+
+const (
+	KindFacts       Kind = "facts"
+	KindLintResults Kind = "lint results"
+	KindPackages    Kind = "packages"
+)
+
 // Cache is a per-package data cache. A cached data is invalidated when
 // package or it's dependencies change.
 type Cache struct {
 	lowLevelCache *cache.Cache
 	pkgHashes     sync.Map
 	sw            *timeutils.Stopwatch
+	tracer        *timeutils.Tracer
 	log           logutils.Log  // not used now, but may be needed for future debugging purposes
 	ioSem         chan struct{} // semaphore limiting parallel IO
+	roots         []root
+	usedKeys      sync.Map // keyUse -> true, see SaveUsedKeys
+	usedDataIDs   sync.Map // cache.ActionID -> true: IDs of data not tied to packages, see EntryIDs
 }
 
-func NewCache(sw *timeutils.Stopwatch, log logutils.Log) (*Cache, error) {
+// keyUse is a key of data cached for packages, e.g. facts of an analyzer.
+type keyUse struct {
+	Mode HashMode
+	Key  string
+}
+
+// root is a directory replaced by its name in cache keys and cached paths:
+// a cache restored into a checkout at another path stays valid.
+type root struct {
+	name string
+	dir  string
+}
+
+func NewCache(sw *timeutils.Stopwatch, tracer *timeutils.Tracer, log logutils.Log) (*Cache, error) {
 	c, err := cache.Default()
 	if err != nil {
 		return nil, err
@@ -43,18 +76,138 @@ func NewCache(sw *timeutils.Stopwatch, log logutils.Log) (*Cache, error) {
 	return &Cache{
 		lowLevelCache: c,
 		sw:            sw,
+		tracer:        tracer,
 		log:           log,
 		ioSem:         make(chan struct{}, runtime.GOMAXPROCS(-1)),
 	}, nil
 }
 
+// SetBackend makes the cache read through and write through the shared backend.
+func (c *Cache) SetBackend(backend cache.Backend) {
+	c.lowLevelCache.SetBackend(backend)
+}
+
+// FlushBackend waits for writes to the shared backend and returns its first error.
+func (c *Cache) FlushBackend() error {
+	return c.lowLevelCache.FlushBackend()
+}
+
+// SetTrimLimits sets the size and age limits of the cache enforced by Trim.
+func (c *Cache) SetTrimLimits(limits cache.TrimLimits) {
+	c.lowLevelCache.SetTrimLimits(limits)
+}
+
+// SetRoots sets directories (e.g. the module root or GOROOT) by their names (e.g. "GOROOT")
+// to make cache keys and cached paths relative to them. It must be called before
+// any cache access.
+func (c *Cache) SetRoots(roots map[string]string) {
+	c.roots = nil
+	for name, dir := range roots {
+		if dir == "" {
+			continue
+		}
+		if absDir, err := filepath.Abs(dir); err == nil {
+			dir = absDir
+		}
+		c.roots = append(c.roots, root{name: rootSentinel(name), dir: filepath.Clean(dir)})
+	}
+
+	// Nested roots: match the deepest one first.
+	sort.Slice(c.roots, func(i, j int) bool {
+		return len(c.roots[i].dir) > len(c.roots[j].dir)
+	})
+}
+
+// rootSentinel returns the name of the root to store in the cache instead of its directory:
+// NUL bytes don't occur in paths and linter messages, so the name can't collide with them.
+func rootSentinel(name string) string {
+	return "\x00" + name + "\x00"
+}
+
+// RelPath returns the path relative to the root containing it to store in the cache.
+// Paths out of roots are returned as is.
+func (c *Cache) RelPath(path string) string {
+	for _, r := range c.roots {
+		rel, err := filepath.Rel(r.dir, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		return r.name + "/" + filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// AbsPath restores the path returned by RelPath.
+func (c *Cache) AbsPath(path string) string {
+	for _, r := range c.roots {
+		if strings.HasPrefix(path, r.name+"/") {
+			return filepath.Join(r.dir, filepath.FromSlash(strings.TrimPrefix(path, r.name+"/")))
+		}
+	}
+	return path
+}
+
+// RelText replaces roots in the text (e.g. a linter message with a file path) by their names.
+// Only whole paths are replaced: "/src/a" isn't replaced in "/src/ab" or in "/x/src/a".
+func (c *Cache) RelText(text string) string {
+	for _, r := range c.roots {
+		text = replacePath(text, r.dir, r.name)
+	}
+	return text
+}
+
+// AbsText restores the text returned by RelText.
+func (c *Cache) AbsText(text string) string {
+	for _, r := range c.roots {
+		text = strings.ReplaceAll(text, r.name, r.dir)
+	}
+	return text
+}
+
+// replacePath replaces occurrences of the directory in the text which are at path boundaries:
+// not preceded by a path and followed by a separator or a character which isn't in file names.
+// The directory is cleaned, so it doesn't end with a separator.
+func replacePath(text, dir, name string) string {
+	var b strings.Builder
+	for {
+		idx := strings.Index(text, dir)
+		if idx == -1 {
+			b.WriteString(text)
+			return b.String()
+		}
+
+		end := idx + len(dir)
+		before, after := rune(0), rune(0)
+		if idx > 0 {
+			before = rune(text[idx-1])
+		}
+		if end < len(text) {
+			after = rune(text[end])
+		}
+
+		b.WriteString(text[:idx])
+		if !isPathChar(before) && !os.IsPathSeparator(uint8(before)) && !isPathChar(after) {
+			b.WriteString(name)
+		} else {
+			b.WriteString(dir)
+		}
+		text = text[end:]
+	}
+}
+
+// isPathChar reports whether the character can be a part of a file name in a linter message.
+func isPathChar(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' ||
+		r == '.' || r == '_' || r == '-' || r == '~' || r == '+' || r == '@' || r >= utf8.RuneSelf
+}
+
 func (c *Cache) Trim() {
 	c.sw.TrackStage("trim", func() {
 		c.lowLevelCache.Trim()
 	})
 }
 
-func (c *Cache) Put(pkg *packages.Package, mode HashMode, key string, data interface{}) error {
+func (c *Cache) Put(pkg *packages.Package, mode HashMode, kind Kind, key string, data interface{}) error {
 	var err error
 	buf := &bytes.Buffer{}
 	c.sw.TrackStage("gob", func() {
@@ -79,10 +232,13 @@ func (c *Cache) Put(pkg *packages.Package, mode HashMode, key string, data inter
 	if err != nil {
 		return errors.Wrapf(err, "failed to calculate package %s action id", pkg.Name)
 	}
+	c.usedKeys.Store(keyUse{Mode: mode, Key: key}, true)
 	c.ioSem <- struct{}{}
+	endSpan := c.tracer.Start("cache", fmt.Sprintf("put %s %s", pkg.PkgPath, key))
 	c.sw.TrackStage("cache io", func() {
-		err = c.lowLevelCache.PutBytes(aID, buf.Bytes())
+		err = c.lowLevelCache.PutBytesKind(aID, string(kind), buf.Bytes())
 	})
+	endSpan()
 	<-c.ioSem
 	if err != nil {
 		return errors.Wrapf(err, "failed to save data to low-level cache by key %s for package %s", key, pkg.Name)
@@ -91,8 +247,60 @@ func (c *Cache) Put(pkg *packages.Package, mode HashMode, key string, data inter
 	return nil
 }
 
+// PutData saves data not tied to a package (e.g. the loaded packages graph) by the key.
+func (c *Cache) PutData(kind Kind, key cache.ActionID, data interface{}) error {
+	buf := &bytes.Buffer{}
+	var err error
+	c.sw.TrackStage("gob", func() {
+		err = gob.NewEncoder(buf).Encode(data)
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to gob encode")
+	}
+
+	endSpan := c.tracer.Start("cache", fmt.Sprintf("put %s", kind))
+	c.sw.TrackStage("cache io", func() {
+		err = c.lowLevelCache.PutBytesKind(key, string(kind), buf.Bytes())
+	})
+	endSpan()
+	if err != nil {
+		return errors.Wrapf(err, "failed to save %s to low-level cache", kind)
+	}
+	c.usedDataIDs.Store(key, true)
+	return nil
+}
+
+// GetData loads data saved by PutData.
+func (c *Cache) GetData(kind Kind, key cache.ActionID, data interface{}) error {
+	var b []byte
+	var err error
+	endSpan := c.tracer.Start("cache", fmt.Sprintf("get %s", kind))
+	c.sw.TrackStage("cache io", func() {
+		b, _, err = c.lowLevelCache.GetBytes(key)
+	})
+	endSpan()
+	if err != nil {
+		if cache.IsErrMissing(err) {
+			return ErrMissing
+		}
+		return errors.Wrapf(err, "failed to get %s from low-level cache", kind)
+	}
+	c.usedDataIDs.Store(key, true)
+
+	c.sw.TrackStage("gob", func() {
+		err = gob.NewDecoder(bytes.NewReader(b)).Decode(data)
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to gob decode")
+	}
+	return nil
+}
+
 var ErrMissing = errors.New("missing data")
 
+// usedKeysLockFile is a lock file in the cache directory guarding the lists of used keys.
+const usedKeysLockFile = "used-keys.lock"
+
 func (c *Cache) Get(pkg *packages.Package, mode HashMode, key string, data interface{}) error {
 	var aID cache.ActionID
 	var err error
@@ -109,12 +317,15 @@ func (c *Cache) Get(pkg *packages.Package, mode HashMode, key string, data inter
 	if err != nil {
 		return errors.Wrapf(err, "failed to calculate package %s action id", pkg.Name)
 	}
+	c.usedKeys.Store(keyUse{Mode: mode, Key: key}, true)
 
 	var b []byte
 	c.ioSem <- struct{}{}
+	endSpan := c.tracer.Start("cache", fmt.Sprintf("get %s %s", pkg.PkgPath, key))
 	c.sw.TrackStage("cache io", func() {
 		b, _, err = c.lowLevelCache.GetBytes(aID)
 	})
+	endSpan()
 	<-c.ioSem
 	if err != nil {
 		if cache.IsErrMissing(err) {
@@ -133,6 +344,134 @@ func (c *Cache) Get(pkg *packages.Package, mode HashMode, key string, data inter
 	return nil
 }
 
+// usedKeysID is the ID of the list of keys used by runs with the same salt and scope
+// (enabled linters and their settings): it doesn't depend on packages, so the keys
+// don't depend on them too.
+func usedKeysID(scope string) (cache.ActionID, error) {
+	h, err := cache.NewHash("used keys")
+	if err != nil {
+		return cache.ActionID{}, errors.Wrap(err, "failed to make a hash")
+	}
+	fmt.Fprintf(h, "scope %q\n", scope)
+	return h.Sum(), nil
+}
+
+func (c *Cache) loadUsedKeys(scope string) ([]keyUse, error) {
+	id, err := usedKeysID(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	b, _, err := c.lowLevelCache.GetBytes(id)
+	if err != nil {
+		if cache.IsErrMissing(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to get used keys")
+	}
+
+	var keys []keyUse
+	if err = json.Unmarshal(b, &keys); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal used keys")
+	}
+	return keys, nil
+}
+
+// SaveUsedKeys replaces the list of keys of the scope (enabled linters and their settings)
+// by keys used by Get and Put in this run: keys of disabled linters or changed settings
+// are pruned. EntryIDs finds entries of packages by them.
+func (c *Cache) SaveUsedKeys(scope string) error {
+	var keys []keyUse
+	c.usedKeys.Range(func(k, _ interface{}) bool {
+		keys = append(keys, k.(keyUse))
+		return true
+	})
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Key != keys[j].Key {
+			return keys[i].Key < keys[j].Key
+		}
+		return keys[i].Mode < keys[j].Mode
+	})
+
+	b, err := json.Marshal(keys)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal used keys")
+	}
+	id, err := usedKeysID(scope)
+	if err != nil {
+		return err
+	}
+
+	// Runs in other modules share the cache directory: serialize writers of the list.
+	lock := flock.New(filepath.Join(cache.DefaultDir(), usedKeysLockFile))
+	if err := lock.Lock(); err != nil {
+		return errors.Wrap(err, "failed to lock used keys")
+	}
+	defer lock.Unlock() //nolint:errcheck
+
+	return c.lowLevelCache.PutBytes(id, b)
+}
+
+// EntryIDs returns IDs of entries of the packages and their dependencies
+// by keys used by the last run with the same salt and scope, see SaveUsedKeys,
+// and IDs of data not tied to packages (e.g. the loaded packages graph) used by GetData and PutData.
+func (c *Cache) EntryIDs(pkgs []*packages.Package, scope string) ([]cache.ActionID, error) {
+	keys, err := c.loadUsedKeys(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	keysID, err := usedKeysID(scope)
+	if err != nil {
+		return nil, err
+	}
+	ids := []cache.ActionID{keysID}
+	c.usedDataIDs.Range(func(id, _ interface{}) bool {
+		ids = append(ids, id.(cache.ActionID))
+		return true
+	})
+
+	visited := map[*packages.Package]bool{}
+	var visit func(pkg *packages.Package) error
+	visit = func(pkg *packages.Package) error {
+		if visited[pkg] {
+			return nil
+		}
+		visited[pkg] = true
+
+		for _, k := range keys {
+			aID, err := c.pkgActionID(pkg, k.Mode)
+			if err != nil {
+				return errors.Wrapf(err, "failed to calculate package %s action id", pkg.Name)
+			}
+			subkey, err := cache.Subkey(aID, k.Key)
+			if err != nil {
+				return errors.Wrap(err, "failed to build subkey")
+			}
+			ids = append(ids, subkey)
+		}
+
+		for _, imp := range pkg.Imports {
+			if err := visit(imp); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, pkg := range pkgs {
+		if err := visit(pkg); err != nil {
+			return nil, err
+		}
+	}
+	return ids, nil
+}
+
+// Export writes entries with the IDs into the tar stream, see cache.Cache.Export.
+func (c *Cache) Export(w io.Writer, ids []cache.ActionID) (int, error) {
+	return c.lowLevelCache.Export(w, ids)
+}
+
 func (c *Cache) pkgActionID(pkg *packages.Package, mode HashMode) (cache.ActionID, error) {
 	hash, err := c.packageHash(pkg, mode)
 	if err != nil {
@@ -178,7 +517,7 @@ func (c *Cache) packageHash(pkg *packages.Package, mode HashMode) (string, error
 		if fErr != nil {
 			return "", errors.Wrapf(fErr, "failed to calculate file %s hash", f)
 		}
-		fmt.Fprintf(key, "file %s %x\n", f, h)
+		fmt.Fprintf(key, "file %s %x\n", c.RelPath(f), h)
 	}
 	curSum := key.Sum()
 	hashRes[HashModeNeedOnlySelf] = hex.EncodeToString(curSum[:])
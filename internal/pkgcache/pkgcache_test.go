@@ -0,0 +1,85 @@
+package pkgcache
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func newTestCache(t *testing.T) (*Cache, string) {
+	t.Helper()
+
+	modRoot, err := filepath.Abs(filepath.Join("testdata", "src", "a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &Cache{}
+	c.SetRoots(map[string]string{
+		"MODROOT":    modRoot,
+		"GOMODCACHE": filepath.Join(modRoot, "pkg", "mod"),
+		"GOROOT":     "",
+	})
+	return c, modRoot
+}
+
+func TestRelPath(t *testing.T) {
+	c, modRoot := newTestCache(t)
+
+	tests := []struct {
+		path string
+		rel  string
+	}{
+		{filepath.Join(modRoot, "b", "b.go"), rootSentinel("MODROOT") + "/b/b.go"},
+		{filepath.Join(modRoot, "pkg", "mod", "m.go"), rootSentinel("GOMODCACHE") + "/m.go"},
+		{modRoot + "b", modRoot + "b"},
+		{filepath.Dir(modRoot), filepath.Dir(modRoot)},
+	}
+	for _, test := range tests {
+		rel := c.RelPath(test.path)
+		if rel != test.rel {
+			t.Errorf("RelPath(%q) = %q, want %q", test.path, rel, test.rel)
+		}
+		if abs := c.AbsPath(rel); abs != test.path {
+			t.Errorf("AbsPath(%q) = %q, want %q", rel, abs, test.path)
+		}
+	}
+}
+
+func TestRelText(t *testing.T) {
+	c, modRoot := newTestCache(t)
+	sep := string(filepath.Separator)
+
+	tests := []struct {
+		text string
+		rel  string
+	}{
+		{
+			text: modRoot + sep + "b.go:1: error",
+			rel:  rootSentinel("MODROOT") + sep + "b.go:1: error",
+		},
+		{
+			text: "cannot find " + modRoot + ", see " + modRoot + sep + "c",
+			rel:  "cannot find " + rootSentinel("MODROOT") + ", see " + rootSentinel("MODROOT") + sep + "c",
+		},
+		{
+			text: `imports "` + filepath.Join(modRoot, "pkg", "mod", "x") + `"`,
+			rel:  `imports "` + rootSentinel("GOMODCACHE") + sep + `x"`,
+		},
+		// Not at path boundaries: a longer name or a nested directory.
+		{text: modRoot + "b" + sep + "b.go", rel: modRoot + "b" + sep + "b.go"},
+		{text: modRoot + ".go", rel: modRoot + ".go"},
+		{text: sep + "x" + modRoot, rel: sep + "x" + modRoot},
+		{text: "x" + modRoot, rel: "x" + modRoot},
+		// Text looking like an old root name stays as is.
+		{text: "$GOROOT and $MODROOT", rel: "$GOROOT and $MODROOT"},
+	}
+	for _, test := range tests {
+		rel := c.RelText(test.text)
+		if rel != test.rel {
+			t.Errorf("RelText(%q) = %q, want %q", test.text, rel, test.rel)
+		}
+		if abs := c.AbsText(rel); abs != test.text {
+			t.Errorf("AbsText(%q) = %q, want %q", rel, abs, test.text)
+		}
+	}
+}
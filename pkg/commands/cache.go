@@ -1,15 +1,22 @@
 package commands
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"github.com/golangci/golangci-lint/internal/cache"
+	"github.com/golangci/golangci-lint/internal/pkgcache"
 	"github.com/golangci/golangci-lint/pkg/fsutils"
 	"github.com/golangci/golangci-lint/pkg/logutils"
+	"github.com/golangci/golangci-lint/pkg/result/processors"
 )
 
 func (e *Executor) initCache() {
@@ -27,18 +34,60 @@ func (e *Executor) initCache() {
 	}
 	e.rootCmd.AddCommand(cacheCmd)
 
-	cacheCmd.AddCommand(&cobra.Command{
+	cleanCmd := &cobra.Command{
 		Use:   "clean",
 		Short: "Clean cache",
 		Run:   e.executeCleanCache,
-	})
+	}
+	cleanCmd.Flags().DurationVar(&e.cfg.Run.LockWait, "lock-wait", defaultLockWait, wh(lockWaitDesc))
+	cacheCmd.AddCommand(cleanCmd)
 	cacheCmd.AddCommand(&cobra.Command{
 		Use:   "status",
 		Short: "Show cache status",
 		Run:   e.executeCacheStatus,
 	})
 
-	// TODO: add trim command?
+	trimCmd := &cobra.Command{
+		Use:   "trim",
+		Short: "Remove least recently used cache entries exceeding the size and age limits",
+		Run:   e.executeTrimCache,
+	}
+	fs := trimCmd.Flags()
+	fs.SortFlags = false // sort them as they are defined here
+	fs.StringVar(&e.cfg.Run.CacheMaxSize, "max-size", "", wh("Max cache size like 2GiB, default is run.cache-max-size"))
+	fs.DurationVar(&e.cfg.Run.CacheMaxAge, "max-age", 0,
+		wh("Max time since the last use of an entry, default is run.cache-max-age or 120h"))
+	fs.DurationVar(&e.cfg.Run.LockWait, "lock-wait", defaultLockWait, wh(lockWaitDesc))
+	cacheCmd.AddCommand(trimCmd)
+
+	var exportFor []string
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write cache entries of packages as a tar stream to stdout, e.g. `cache export --for ./... | zstd > cache.tar.zst`",
+		Run: func(_ *cobra.Command, args []string) {
+			e.executeExportCache(exportFor, args)
+		},
+	}
+	e.initRunConfiguration(exportCmd) // allow --config, --build-tags etc. to match the run
+	exportCmd.Flags().StringSliceVar(&exportFor, "for", []string{"./..."},
+		wh("Packages to export entries for: their dependencies and the config are taken into account"))
+	cacheCmd.AddCommand(exportCmd)
+
+	importCmd := &cobra.Command{
+		Use:   "import [file]",
+		Short: "Restore cache entries from the tar stream written by `cache export`, stdin by default",
+		Run:   e.executeImportCache,
+	}
+	importCmd.Flags().DurationVar(&e.cfg.Run.LockWait, "lock-wait", defaultLockWait, wh(lockWaitDesc))
+	cacheCmd.AddCommand(importCmd)
+
+	verifyCmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Remove corrupted or truncated cache entries",
+		Run:   e.executeVerifyCache,
+	}
+	verifyCmd.Flags().DurationVar(&e.cfg.Run.LockWait, "lock-wait", defaultLockWait, wh(lockWaitDesc))
+	cacheCmd.AddCommand(verifyCmd)
 }
 
 func (e *Executor) executeCleanCache(_ *cobra.Command, args []string) {
@@ -46,11 +95,148 @@ func (e *Executor) executeCleanCache(_ *cobra.Command, args []string) {
 		e.log.Fatalf("Usage: golangci-lint cache clean")
 	}
 
+	if err := e.acquireCacheLock(); err != nil {
+		e.log.Fatalf("%s", err)
+	}
+
 	cacheDir := cache.DefaultDir()
 	if err := os.RemoveAll(cacheDir); err != nil {
 		e.log.Fatalf("Failed to remove dir %s: %s", cacheDir, err)
 	}
 
+	e.releaseFileLock()
+	os.Exit(0)
+}
+
+func (e *Executor) executeTrimCache(_ *cobra.Command, args []string) {
+	if len(args) != 0 {
+		e.log.Fatalf("Usage: golangci-lint cache trim")
+	}
+
+	limits, err := e.cacheTrimLimits()
+	if err != nil {
+		e.log.Fatalf("Invalid cache limits: %s", err)
+	}
+
+	// Don't remove entries a parallel run of any module is using.
+	if err = e.acquireCacheLock(); err != nil {
+		e.log.Fatalf("%s", err)
+	}
+
+	c, err := cache.Default()
+	if err != nil {
+		e.log.Fatalf("Failed to open cache: %s", err)
+	}
+	c.SetTrimLimits(limits)
+
+	stats := c.TrimNow()
+	fmt.Fprintf(logutils.StdOut, "Removed %d files (%s), size: %s\n", stats.RemovedFiles,
+		fsutils.PrettifyBytesCount(stats.RemovedBytes), fsutils.PrettifyBytesCount(stats.Size))
+
+	e.releaseFileLock()
+	os.Exit(0)
+}
+
+func (e *Executor) executeExportCache(forPkgs, args []string) {
+	if len(args) != 0 {
+		e.log.Fatalf("Usage: golangci-lint cache export [--for packages]")
+	}
+	if fi, err := os.Stdout.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+		e.log.Fatalf("Refusing to write a tar stream to a terminal: redirect stdout to a file")
+	}
+
+	ctx := context.Background()
+	if err := e.goenv.Discover(ctx); err != nil {
+		e.log.Warnf("Failed to discover go env: %s", err)
+	}
+	e.initCacheRoots()
+
+	e.cfg.Run.Args = forPkgs
+	lintersToRun, err := e.EnabledLintersSet.GetOptimizedLinters()
+	if err != nil {
+		e.log.Fatalf("Failed to get enabled linters: %s", err)
+	}
+
+	// Package hashes don't depend on the load mode: load the same way as the run.
+	lintCtx, err := e.contextLoader.Load(ctx, lintersToRun, processors.NewDiff(&e.cfg.Issues))
+	if err != nil {
+		e.log.Fatalf("Failed to load packages: %s", err)
+	}
+
+	scope, err := e.cacheKeysScope()
+	if err != nil {
+		e.log.Fatalf("Failed to get enabled linters: %s", err)
+	}
+	ids, err := e.pkgCache.EntryIDs(lintCtx.OriginalPackages, scope)
+	if err != nil {
+		e.log.Fatalf("Failed to find cache entries: %s", err)
+	}
+
+	exported, err := e.pkgCache.Export(os.Stdout, ids)
+	if err != nil {
+		e.log.Fatalf("Failed to export cache: %s", err)
+	}
+	fmt.Fprintf(logutils.StdErr, "Exported %d entries\n", exported)
+
+	os.Exit(0)
+}
+
+func (e *Executor) executeImportCache(_ *cobra.Command, args []string) {
+	var r io.Reader = os.Stdin
+	switch len(args) {
+	case 0:
+	case 1:
+		f, err := os.Open(args[0])
+		if err != nil {
+			e.log.Fatalf("Failed to open %s: %s", args[0], err)
+		}
+		defer f.Close()
+		r = f
+	default:
+		e.log.Fatalf("Usage: golangci-lint cache import [file]")
+	}
+
+	if err := e.acquireCacheLock(); err != nil {
+		e.log.Fatalf("%s", err)
+	}
+
+	c, err := cache.Default()
+	if err != nil {
+		e.log.Fatalf("Failed to open cache: %s", err)
+	}
+
+	imported, err := c.Import(r)
+	if err != nil {
+		e.log.Fatalf("Failed to import cache: %s", err)
+	}
+	fmt.Fprintf(logutils.StdOut, "Imported %d entries\n", imported)
+
+	e.releaseFileLock()
+	os.Exit(0)
+}
+
+func (e *Executor) executeVerifyCache(_ *cobra.Command, args []string) {
+	if len(args) != 0 {
+		e.log.Fatalf("Usage: golangci-lint cache verify")
+	}
+
+	if err := e.acquireCacheLock(); err != nil {
+		e.log.Fatalf("%s", err)
+	}
+
+	c, err := cache.Default()
+	if err != nil {
+		e.log.Fatalf("Failed to open cache: %s", err)
+	}
+
+	stats, err := c.Verify()
+	if err != nil {
+		e.log.Fatalf("Failed to verify cache: %s", err)
+	}
+	fmt.Fprintf(logutils.StdOut, "Checked %d entries, removed %d corrupted entries and %d corrupted outputs\n",
+		stats.CheckedEntries, stats.RemovedEntries, stats.RemovedOutputs)
+
+	e.releaseFileLock()
 	os.Exit(0)
 }
 
@@ -66,9 +252,72 @@ func (e *Executor) executeCacheStatus(_ *cobra.Command, args []string) {
 		fmt.Fprintf(logutils.StdOut, "Size: %s\n", fsutils.PrettifyBytesCount(cacheSizeBytes))
 	}
 
+	if c, err := cache.Default(); err == nil {
+		if entries, err := c.Stats(); err == nil {
+			printCacheEntries(entries)
+		}
+	}
+
 	os.Exit(0)
 }
 
+func printCacheEntries(entries map[string]int) {
+	fmt.Fprintln(logutils.StdOut, "Entries:")
+	for _, kind := range []pkgcache.Kind{pkgcache.KindFacts, pkgcache.KindLintResults, pkgcache.KindPackages} {
+		fmt.Fprintf(logutils.StdOut, "  %s: %d\n", kind, entries[string(kind)])
+		delete(entries, string(kind))
+	}
+
+	other := 0
+	for _, count := range entries {
+		other += count
+	}
+	if other != 0 {
+		fmt.Fprintf(logutils.StdOut, "  other: %d\n", other)
+	}
+}
+
+// cacheKeysScope returns the scope of used cache keys: the enabled linters and their settings.
+// Runs with other linters or settings don't add or prune keys of this scope.
+func (e *Executor) cacheKeysScope() (string, error) {
+	enabledLinters, err := e.EnabledLintersSet.GetEnabledLintersMap()
+	if err != nil {
+		return "", err
+	}
+
+	names := make([]string, 0, len(enabledLinters))
+	for name := range enabledLinters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	settingsHash, err := e.cfg.LintersSettings.Hash(names...)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(names, ",") + "\n" + settingsHash, nil
+}
+
+func (e *Executor) saveUsedCacheKeys(pkgCache *pkgcache.Cache) error {
+	scope, err := e.cacheKeysScope()
+	if err != nil {
+		return err
+	}
+	return pkgCache.SaveUsedKeys(scope)
+}
+
+func (e *Executor) cacheTrimLimits() (cache.TrimLimits, error) {
+	maxSize, err := e.cfg.Run.CacheMaxSizeBytes()
+	if err != nil {
+		return cache.TrimLimits{}, err
+	}
+	if e.cfg.Run.CacheMaxAge < 0 {
+		return cache.TrimLimits{}, errors.New("max age must be positive")
+	}
+
+	return cache.TrimLimits{MaxSize: maxSize, MaxAge: e.cfg.Run.CacheMaxAge}, nil
+}
+
 func dirSizeBytes(path string) (int64, error) {
 	var size int64
 	err := filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
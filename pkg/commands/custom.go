@@ -0,0 +1,280 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime/debug"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"github.com/golangci/golangci-lint/pkg/logutils"
+)
+
+const (
+	defaultCustomConfigFile = ".custom-gcl.yml"
+	defaultCustomBinaryName = "custom-gcl"
+
+	golangciLintModule = "github.com/golangci/golangci-lint"
+
+	// customVersionSuffix is appended to the version of custom binaries.
+	customVersionSuffix = " custom"
+)
+
+// customConfig is the config of `golangci-lint custom` read from .custom-gcl.yml.
+type customConfig struct {
+	// Version of golangci-lint to build: the module must be in the module cache.
+	// Default is the version of the running binary.
+	Version string `yaml:"version"`
+	// Path is an optional directory with golangci-lint sources to build instead of Version.
+	Path string `yaml:"path"`
+	// Name of the binary, default is custom-gcl.
+	Name string `yaml:"name"`
+	// Destination is the directory for the binary, default is the directory of the config.
+	Destination string `yaml:"destination"`
+
+	Plugins []customPlugin `yaml:"plugins"`
+}
+
+// customPlugin is a package exporting the variable AnalyzerPlugin implementing lintersdb.AnalyzerPlugin.
+type customPlugin struct {
+	// Name of the linter: it's configured by linters-settings.custom.<name> with type module.
+	Name    string `yaml:"name"`
+	Module  string `yaml:"module"`
+	Version string `yaml:"version"`
+	// Import is the path of the package, default is Module.
+	Import string `yaml:"import"`
+	// Path is an optional directory with the module sources to build instead of Version.
+	Path string `yaml:"path"`
+}
+
+// releaseVersionRe matches the whole version: development builds like "1.2.3-4-gabcdef" aren't releases.
+var releaseVersionRe = regexp.MustCompile(`^v?\d+\.\d+\.\d+$`)
+
+func (e *Executor) initCustom() {
+	var configFile string
+	customCmd := &cobra.Command{
+		Use:   "custom",
+		Short: "Build golangci-lint with plugins from " + defaultCustomConfigFile + " compiled in",
+		Run: func(_ *cobra.Command, args []string) {
+			if len(args) != 0 {
+				e.log.Fatalf("Usage: golangci-lint custom")
+			}
+			if err := e.executeCustom(configFile); err != nil {
+				e.log.Fatalf("Failed to build custom golangci-lint: %s", err)
+			}
+			os.Exit(0)
+		},
+	}
+	customCmd.Flags().StringVar(&configFile, "file", defaultCustomConfigFile, wh("Path to the config of the custom build"))
+	e.rootCmd.AddCommand(customCmd)
+}
+
+func (e *Executor) executeCustom(configFile string) error {
+	cfg, err := readCustomConfig(configFile)
+	if err != nil {
+		return err
+	}
+	if cfg.Version == "" && cfg.Path == "" {
+		if cfg.Version = e.releaseVersion(); cfg.Version == "" {
+			return fmt.Errorf("can't get the release version of this golangci-lint (%s), set version or path in %s",
+				e.version, configFile)
+		}
+	}
+
+	dir, err := ioutil.TempDir("", "golangci-lint-custom-")
+	if err != nil {
+		return errors.Wrap(err, "failed to make temp dir")
+	}
+	defer os.RemoveAll(dir)
+
+	version := cfg.Version
+	if version == "" {
+		version = e.version // built from the path
+	}
+	if err = writeCustomModule(dir, cfg, version); err != nil {
+		return err
+	}
+
+	binPath := filepath.Join(cfg.Destination, cfg.Name)
+	if err = buildCustomModule(dir, binPath); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(logutils.StdOut, "Built %s with plugins:", binPath)
+	for _, p := range cfg.Plugins {
+		fmt.Fprintf(logutils.StdOut, " %s", p.Name)
+	}
+	fmt.Fprintln(logutils.StdOut)
+	return nil
+}
+
+// releaseVersion returns the module version of this binary or "" for development builds.
+// A custom binary is built from the version it was built from.
+func (e *Executor) releaseVersion() string {
+	version := strings.TrimSuffix(e.version, customVersionSuffix)
+	if releaseVersionRe.MatchString(version) {
+		return "v" + strings.TrimPrefix(version, "v")
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Path == golangciLintModule &&
+		releaseVersionRe.MatchString(info.Main.Version) {
+		return info.Main.Version
+	}
+	return ""
+}
+
+// readCustomConfig reads and validates the config: relative paths are resolved from the config directory.
+func readCustomConfig(configFile string) (*customConfig, error) {
+	data, err := ioutil.ReadFile(configFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read config")
+	}
+
+	var cfg customConfig
+	if err = yaml.UnmarshalStrict(data, &cfg); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse config %s", configFile)
+	}
+
+	configDir, err := filepath.Abs(filepath.Dir(configFile))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get config directory")
+	}
+	absPath := func(path string) string {
+		if path == "" || filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Join(configDir, path)
+	}
+
+	if cfg.Name == "" {
+		cfg.Name = defaultCustomBinaryName
+	}
+	if cfg.Destination == "" {
+		cfg.Destination = configDir
+	}
+	cfg.Destination = absPath(cfg.Destination)
+	cfg.Path = absPath(cfg.Path)
+
+	if len(cfg.Plugins) == 0 {
+		return nil, fmt.Errorf("no plugins in config %s", configFile)
+	}
+	names := map[string]bool{}
+	for i := range cfg.Plugins {
+		p := &cfg.Plugins[i]
+		switch {
+		case p.Name == "":
+			return nil, fmt.Errorf("plugin %d in config %s has no name", i, configFile)
+		case names[p.Name]:
+			return nil, fmt.Errorf("plugin name %s in config %s isn't unique", p.Name, configFile)
+		case p.Module == "":
+			return nil, fmt.Errorf("plugin %s in config %s has no module", p.Name, configFile)
+		case p.Version == "" && p.Path == "":
+			return nil, fmt.Errorf("plugin %s in config %s has neither version nor path", p.Name, configFile)
+		}
+		names[p.Name] = true
+
+		if p.Import == "" {
+			p.Import = p.Module
+		}
+		p.Path = absPath(p.Path)
+	}
+
+	return &cfg, nil
+}
+
+var customMainTemplate = template.Must(template.New("main").Parse(`// Code generated by golangci-lint custom. DO NOT EDIT.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/golangci/golangci-lint/pkg/commands"
+	"github.com/golangci/golangci-lint/pkg/exitcodes"
+	"github.com/golangci/golangci-lint/pkg/lint/lintersdb"
+{{range $i, $p := .Plugins}}
+	plugin{{$i}} {{printf "%q" $p.Import}}{{end}}
+)
+
+func init() {
+{{- range $i, $p := .Plugins}}
+	lintersdb.RegisterPlugin({{printf "%q" $p.Name}}, plugin{{$i}}.AnalyzerPlugin){{end}}
+}
+
+func main() {
+	e := commands.NewExecutor({{printf "%q" .Version}}, "?", "")
+
+	if err := e.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed executing command with error %v\n", err)
+		os.Exit(exitcodes.Failure)
+	}
+}
+`))
+
+// writeCustomModule writes the module of the custom binary: its main registers the plugins.
+func writeCustomModule(dir string, cfg *customConfig, version string) error {
+	var goMod bytes.Buffer
+	fmt.Fprintf(&goMod, "module golangci-lint-custom\n\ngo 1.12\n\nrequire (\n")
+	requireVersion := func(version string) string {
+		if version == "" {
+			return "v0.0.0" // replaced by a local path
+		}
+		return version
+	}
+	fmt.Fprintf(&goMod, "\t%s %s\n", golangciLintModule, requireVersion(cfg.Version))
+	for _, p := range cfg.Plugins {
+		fmt.Fprintf(&goMod, "\t%s %s\n", p.Module, requireVersion(p.Version))
+	}
+	fmt.Fprintf(&goMod, ")\n")
+
+	if cfg.Path != "" {
+		fmt.Fprintf(&goMod, "\nreplace %s => %s\n", golangciLintModule, cfg.Path)
+	}
+	for _, p := range cfg.Plugins {
+		if p.Path != "" {
+			fmt.Fprintf(&goMod, "\nreplace %s => %s\n", p.Module, p.Path)
+		}
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "go.mod"), goMod.Bytes(), 0644); err != nil {
+		return errors.Wrap(err, "failed to write go.mod")
+	}
+
+	var main bytes.Buffer
+	err := customMainTemplate.Execute(&main, map[string]interface{}{
+		"Plugins": cfg.Plugins,
+		"Version": version + customVersionSuffix,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to generate main.go")
+	}
+	if err = ioutil.WriteFile(filepath.Join(dir, "main.go"), main.Bytes(), 0644); err != nil {
+		return errors.Wrap(err, "failed to write main.go")
+	}
+	return nil
+}
+
+// buildCustomModule builds the binary offline: modules are taken from the module cache
+// where they were verified on download, so the checksum database isn't used too.
+func buildCustomModule(dir, binPath string) error {
+	cmd := exec.Command("go", "build", "-o", binPath, ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOSUMDB=off", "GOWORK=off")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("go build failed: %s: %s", err, strings.TrimSpace(out.String()))
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomReleaseVersion(t *testing.T) {
+	cases := map[string]string{
+		"1.2.3":          "v1.2.3",
+		"v1.2.3":         "v1.2.3",
+		"v1.2.3 custom":  "v1.2.3", // a custom binary builds the version it was built from
+		"1.2.3-4-gabcde": "",
+		"v1.2.3-dirty":   "",
+		"(devel)":        "",
+		"":               "",
+	}
+	for version, expected := range cases {
+		e := &Executor{version: version}
+		assert.Equal(t, expected, e.releaseVersion(), version)
+	}
+}
+
+func writeCustomConfig(t *testing.T, content string) (dir, path string) {
+	dir, err := ioutil.TempDir("", "golangci-lint-custom-")
+	require.NoError(t, err)
+	path = filepath.Join(dir, defaultCustomConfigFile)
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+	return dir, path
+}
+
+func TestReadCustomConfig(t *testing.T) {
+	dir, path := writeCustomConfig(t, `
+path: ../golangci-lint
+plugins:
+  - name: foo
+    module: example.com/foo
+    version: v1.0.0
+  - name: bar
+    module: example.com/bar
+    import: example.com/bar/plugin
+    path: /src/bar
+  - name: baz
+    module: example.com/baz
+    path: baz
+`)
+	defer os.RemoveAll(dir)
+
+	cfg, err := readCustomConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, &customConfig{
+		Path:        filepath.Join(filepath.Dir(dir), "golangci-lint"),
+		Name:        defaultCustomBinaryName,
+		Destination: dir,
+		Plugins: []customPlugin{
+			{Name: "foo", Module: "example.com/foo", Version: "v1.0.0", Import: "example.com/foo"},
+			{Name: "bar", Module: "example.com/bar", Import: "example.com/bar/plugin", Path: "/src/bar"},
+			{Name: "baz", Module: "example.com/baz", Import: "example.com/baz", Path: filepath.Join(dir, "baz")},
+		},
+	}, cfg)
+}
+
+func TestReadCustomConfigInvalid(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{
+			name:    "no plugins",
+			content: "version: v1.2.3\n",
+			err:     "no plugins in config",
+		},
+		{
+			name:    "unknown field",
+			content: "plugins:\n  - name: foo\n    module: example.com/foo\n    version: v1.0.0\n    unknown: x\n",
+			err:     "failed to parse config",
+		},
+		{
+			name:    "missing name",
+			content: "plugins:\n  - module: example.com/foo\n    version: v1.0.0\n",
+			err:     "plugin 0 in config",
+		},
+		{
+			name:    "missing module",
+			content: "plugins:\n  - name: foo\n    version: v1.0.0\n",
+			err:     "plugin foo in config .* has no module",
+		},
+		{
+			name:    "missing version and path",
+			content: "plugins:\n  - name: foo\n    module: example.com/foo\n",
+			err:     "plugin foo in config .* has neither version nor path",
+		},
+		{
+			name: "duplicate name",
+			content: "plugins:\n" +
+				"  - name: foo\n    module: example.com/foo\n    version: v1.0.0\n" +
+				"  - name: foo\n    module: example.com/bar\n    version: v1.0.0\n",
+			err: "plugin name foo in config .* isn't unique",
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			dir, path := writeCustomConfig(t, c.content)
+			defer os.RemoveAll(dir)
+
+			_, err := readCustomConfig(path)
+			require.Error(t, err)
+			assert.Regexp(t, c.err, err.Error())
+		})
+	}
+}
+
+func TestWriteCustomModule(t *testing.T) {
+	dir, err := ioutil.TempDir("", "golangci-lint-custom-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := &customConfig{
+		Version: "v1.2.3",
+		Plugins: []customPlugin{
+			{Name: "foo", Module: "example.com/foo", Version: "v1.0.0", Import: "example.com/foo"},
+			{Name: "bar", Module: "example.com/bar", Import: "example.com/bar/plugin", Path: "/src/bar"},
+		},
+	}
+	require.NoError(t, writeCustomModule(dir, cfg, cfg.Version))
+
+	goMod, err := ioutil.ReadFile(filepath.Join(dir, "go.mod"))
+	require.NoError(t, err)
+	assert.Equal(t, `module golangci-lint-custom
+
+go 1.12
+
+require (
+	github.com/golangci/golangci-lint v1.2.3
+	example.com/foo v1.0.0
+	example.com/bar v0.0.0
+)
+
+replace example.com/bar => /src/bar
+`, string(goMod))
+
+	main, err := ioutil.ReadFile(filepath.Join(dir, "main.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(main), `
+	plugin0 "example.com/foo"
+	plugin1 "example.com/bar/plugin"
+)`)
+	assert.Contains(t, string(main), `
+func init() {
+	lintersdb.RegisterPlugin("foo", plugin0.AnalyzerPlugin)
+	lintersdb.RegisterPlugin("bar", plugin1.AnalyzerPlugin)
+}`)
+	assert.Contains(t, string(main), `commands.NewExecutor("v1.2.3 custom", "?", "")`)
+}
+
+func TestWriteCustomModuleFromPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "golangci-lint-custom-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := &customConfig{
+		Path: "/src/golangci-lint",
+		Plugins: []customPlugin{
+			{Name: "foo", Module: "example.com/foo", Version: "v1.0.0", Import: "example.com/foo"},
+		},
+	}
+	require.NoError(t, writeCustomModule(dir, cfg, "(devel)"))
+
+	goMod, err := ioutil.ReadFile(filepath.Join(dir, "go.mod"))
+	require.NoError(t, err)
+	assert.Contains(t, string(goMod), "\tgithub.com/golangci/golangci-lint v0.0.0\n")
+	assert.Contains(t, string(goMod), "\nreplace github.com/golangci/golangci-lint => /src/golangci-lint\n")
+}
@@ -4,8 +4,9 @@ import (
 	"bytes"
 	"context"
 	"crypto/sha256"
-	"encoding/json"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
@@ -50,9 +51,14 @@ type Executor struct {
 	pkgCache          *pkgcache.Cache
 	debugf            logutils.DebugFunc
 	sw                *timeutils.Stopwatch
-
-	loadGuard *load.Guard
-	flock     *flock.Flock
+	inFlight          *timeutils.InFlight
+	profile           *report.Profile
+	tracer            *timeutils.Tracer
+	progress          *report.Progress
+
+	loadGuard  *load.Guard
+	flock      *flock.Flock // the lock of runs of the module, see lockFilePath
+	cacheFlock *flock.Flock // the lock of the cache directory, see cacheLockFilePath
 }
 
 func NewExecutor(version, commit, date string) *Executor {
@@ -100,6 +106,7 @@ func NewExecutor(version, commit, date string) *Executor {
 	e.initCompletion()
 	e.initVersion()
 	e.initCache()
+	e.initCustom()
 
 	// init e.cfg by values from config: flags parse will see these values
 	// like the default ones. It will overwrite them only if the same option
@@ -129,13 +136,28 @@ func NewExecutor(version, commit, date string) *Executor {
 	e.lineCache = fsutils.NewLineCache(e.fileCache)
 
 	e.sw = timeutils.NewStopwatch("pkgcache", e.log.Child("stopwatch"))
-	e.pkgCache, err = pkgcache.NewCache(e.sw, e.log.Child("pkgcache"))
+	e.inFlight = timeutils.NewInFlight()
+	e.inFlight.AddStopwatch("pkgcache", e.sw)
+	e.profile = report.NewProfile()
+	e.profile.AddStagesStopwatch("pkgcache", e.sw)
+	if commandLineCfg != nil && commandLineCfg.Run.TraceEventsPath != "" {
+		e.tracer = timeutils.NewTracer()
+	}
+	e.pkgCache, err = pkgcache.NewCache(e.sw, e.tracer, e.log.Child("pkgcache"))
 	if err != nil {
 		e.log.Fatalf("Failed to build packages cache: %s", err)
 	}
+	if err = e.initCacheBackend(commandLineCfg); err != nil {
+		e.log.Fatalf("Failed to init cache backend: %s", err)
+	}
+	cacheLimits, err := e.cacheTrimLimits()
+	if err != nil {
+		e.log.Fatalf("Invalid cache limits: %s", err)
+	}
+	e.pkgCache.SetTrimLimits(cacheLimits)
 	e.loadGuard = load.NewGuard()
 	e.contextLoader = lint.NewContextLoader(e.cfg, e.log.Child("loader"), e.goenv,
-		e.lineCache, e.fileCache, e.pkgCache, e.loadGuard)
+		e.lineCache, e.fileCache, e.pkgCache, e.loadGuard, e.profile, e.tracer)
 	if err = e.initHashSalt(version); err != nil {
 		e.log.Fatalf("Failed to init hash salt: %s", err)
 	}
@@ -147,26 +169,40 @@ func (e *Executor) Execute() error {
 	return e.rootCmd.Execute()
 }
 
-func (e *Executor) initHashSalt(version string) error {
-	binSalt, err := computeBinarySalt(version)
+func (e *Executor) initCacheBackend(commandLineCfg *config.Config) error {
+	spec := e.cfg.Run.CacheBackend
+	if commandLineCfg != nil && commandLineCfg.Run.CacheBackend != "" {
+		spec = commandLineCfg.Run.CacheBackend
+	}
+	if spec == "" {
+		return nil
+	}
+
+	backend, err := cache.NewBackend(spec)
 	if err != nil {
-		return errors.Wrap(err, "failed to calculate binary salt")
+		return err
 	}
+	e.pkgCache.SetBackend(backend)
+	e.debugf("Using cache backend %s", spec)
+	return nil
+}
 
-	configSalt, err := computeConfigSalt(e.cfg)
+func (e *Executor) initHashSalt(version string) error {
+	binSalt, err := computeBinarySalt(version)
 	if err != nil {
-		return errors.Wrap(err, "failed to calculate config salt")
+		return errors.Wrap(err, "failed to calculate binary salt")
 	}
 
 	var b bytes.Buffer
 	b.Write(binSalt)
-	b.Write(configSalt)
+	b.Write(computeConfigSalt(e.cfg))
 	cache.SetSalt(b.Bytes())
 	return nil
 }
 
 func computeBinarySalt(version string) ([]byte, error) {
-	if version != "" && version != "(devel)" {
+	// Plugins compiled in by `golangci-lint custom` change the binary without changing its version.
+	if version != "" && version != "(devel)" && !lintersdb.HasRegisteredPlugins() {
 		return []byte(version), nil
 	}
 
@@ -190,56 +226,164 @@ func computeBinarySalt(version string) ([]byte, error) {
 	return h.Sum(nil), nil
 }
 
-func computeConfigSalt(cfg *config.Config) ([]byte, error) {
+func computeConfigSalt(cfg *config.Config) []byte {
 	// We don't hash all config fields to reduce meaningless cache
 	// invalidations. At least, it has a huge impact on tests speed.
-
-	lintersSettingsBytes, err := json.Marshal(cfg.LintersSettings)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to json marshal config linter settings")
-	}
+	// Linters settings aren't hashed here: they are a part of cache keys
+	// of each linter, see goanalysis.getIssuesCacheGroups.
 
 	var configData bytes.Buffer
-	configData.WriteString("linters-settings=")
-	configData.Write(lintersSettingsBytes)
-	configData.WriteString("\nbuild-tags=%s" + strings.Join(cfg.Run.BuildTags, ","))
+	configData.WriteString("build-tags=%s" + strings.Join(cfg.Run.BuildTags, ","))
 
 	h := sha256.New()
 	h.Write(configData.Bytes()) //nolint:errcheck
-	return h.Sum(nil), nil
+	return h.Sum(nil)
+}
+
+const lockWaitDesc = "How long to wait for a parallel golangci-lint with the same cache and module to finish, " +
+	"0 fails immediately"
+
+// lockFilePath returns the lock file for runs sharing the cache directory and the module:
+// runs in other repositories or with other caches don't wait for each other.
+func lockFilePath() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s", cache.DefaultDir(), findModuleRoot())
+	return filepath.Join(os.TempDir(), fmt.Sprintf("golangci-lint-%x.lock", h.Sum(nil)[:8]))
+}
+
+// cacheLockFilePath returns the lock file of the cache directory: runs of all modules hold it
+// shared, commands changing the whole cache (e.g. `cache trim`) hold it exclusively.
+func cacheLockFilePath() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s", cache.DefaultDir())
+	return filepath.Join(os.TempDir(), fmt.Sprintf("golangci-lint-cache-%x.lock", h.Sum(nil)[:8]))
+}
+
+// findModuleRoot returns the directory with go.mod containing the working directory
+// or the working directory itself: go env isn't discovered yet when the lock is acquired.
+func findModuleRoot() string {
+	wd, err := fsutils.Getwd()
+	if err != nil {
+		return ""
+	}
+
+	for dir := wd; ; {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return wd
+		}
+		dir = parent
+	}
 }
 
-func (e *Executor) acquireFileLock() bool {
+// acquireFileLock locks the cache directory shared and, unless parallel runners are allowed,
+// the module exclusively for the run.
+func (e *Executor) acquireFileLock() error {
+	f, err := e.lockFile(cacheLockFilePath(), true)
+	if err != nil {
+		return err
+	}
+	e.cacheFlock = f
+
 	if e.cfg.Run.AllowParallelRunners {
 		e.debugf("Parallel runners are allowed, no locking")
-		return true
+		return nil
 	}
 
-	lockFile := filepath.Join(os.TempDir(), "golangci-lint.lock")
-	e.debugf("Locking on file %s...", lockFile)
+	if e.flock, err = e.lockFile(lockFilePath(), false); err != nil {
+		return err
+	}
+	return nil
+}
+
+// acquireCacheLock locks the cache directory exclusively: no run of any module uses the cache.
+func (e *Executor) acquireCacheLock() error {
+	f, err := e.lockFile(cacheLockFilePath(), false)
+	if err != nil {
+		return err
+	}
+	e.cacheFlock = f
+	return nil
+}
+
+// lockFile locks the file waiting up to --lock-wait. An exclusive holder writes its PID
+// and command line into the file to be shown to waiting runs.
+func (e *Executor) lockFile(lockFile string, shared bool) (*flock.Flock, error) {
+	e.debugf("Locking on file %s (shared: %t)...", lockFile, shared)
 	f := flock.New(lockFile)
-	const totalTimeout = 5 * time.Second
-	const retryDelay = time.Second
-	ctx, finish := context.WithTimeout(context.Background(), totalTimeout)
-	defer finish()
+	tryLock, tryLockContext := f.TryLock, f.TryLockContext
+	if shared {
+		tryLock, tryLockContext = f.TryRLock, f.TryRLockContext
+	}
 
-	if ok, _ := f.TryLockContext(ctx, retryDelay); !ok {
-		return false
+	ok, err := tryLock()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to lock file %s", lockFile)
 	}
 
-	e.flock = f
-	return true
+	if !ok {
+		holder := readLockHolder(lockFile)
+		if e.cfg.Run.LockWait <= 0 {
+			hint := "use --lock-wait to wait for it"
+			if lockFile == lockFilePath() {
+				hint += " or --allow-parallel-runners"
+			}
+			return nil, fmt.Errorf("parallel golangci-lint is running: %s; %s", holder, hint)
+		}
+
+		e.log.Infof("Waiting up to %s for parallel golangci-lint: %s", e.cfg.Run.LockWait, holder)
+		const retryDelay = 200 * time.Millisecond
+		ctx, finish := context.WithTimeout(context.Background(), e.cfg.Run.LockWait)
+		defer finish()
+		if ok, _ = tryLockContext(ctx, retryDelay); !ok {
+			return nil, fmt.Errorf("parallel golangci-lint is still running after waiting for %s: %s",
+				e.cfg.Run.LockWait, readLockHolder(lockFile))
+		}
+	}
+
+	if !shared {
+		holder := fmt.Sprintf("%d\n%s\n", os.Getpid(), strings.Join(os.Args, " "))
+		if err := ioutil.WriteFile(lockFile, []byte(holder), 0600); err != nil {
+			e.debugf("Failed to write lock holder: %s", err)
+		}
+	}
+	return f, nil
 }
 
-func (e *Executor) releaseFileLock() {
-	if e.cfg.Run.AllowParallelRunners {
-		return
+// readLockHolder returns the PID and the command line of the run holding the lock.
+func readLockHolder(lockFile string) string {
+	data, err := ioutil.ReadFile(lockFile)
+	if err != nil {
+		return fmt.Sprintf("lock %s is held", lockFile)
 	}
 
-	if err := e.flock.Unlock(); err != nil {
-		e.debugf("Failed to unlock on file: %s", err)
+	parts := strings.SplitN(strings.TrimSpace(string(data)), "\n", 2)
+	if len(parts) != 2 {
+		return fmt.Sprintf("lock %s is held", lockFile)
 	}
-	if err := os.Remove(e.flock.Path()); err != nil {
-		e.debugf("Failed to remove lock file: %s", err)
+	return fmt.Sprintf("pid %s (%s) holds lock %s", parts[0], parts[1], lockFile)
+}
+
+// releaseFileLock releases locks taken by acquireFileLock or acquireCacheLock.
+func (e *Executor) releaseFileLock() {
+	for _, f := range []*flock.Flock{e.flock, e.cacheFlock} {
+		if f == nil {
+			continue
+		}
+
+		// Don't remove the lock file: a waiting run could lock the removed file
+		// while another one locks a new file.
+		if f.Locked() {
+			if err := os.Truncate(f.Path(), 0); err != nil {
+				e.debugf("Failed to clear lock holder: %s", err)
+			}
+		}
+		if err := f.Unlock(); err != nil {
+			e.debugf("Failed to unlock on file: %s", err)
+		}
 	}
+	e.flock, e.cacheFlock = nil, nil
 }
@@ -3,9 +3,11 @@ package commands
 import (
 	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"time"
@@ -17,11 +19,14 @@ import (
 
 	"github.com/golangci/golangci-lint/pkg/config"
 	"github.com/golangci/golangci-lint/pkg/exitcodes"
+	"github.com/golangci/golangci-lint/pkg/golinters/goanalysis/load"
+	"github.com/golangci/golangci-lint/pkg/goutil"
 	"github.com/golangci/golangci-lint/pkg/lint"
 	"github.com/golangci/golangci-lint/pkg/lint/lintersdb"
 	"github.com/golangci/golangci-lint/pkg/logutils"
 	"github.com/golangci/golangci-lint/pkg/packages"
 	"github.com/golangci/golangci-lint/pkg/printers"
+	"github.com/golangci/golangci-lint/pkg/report"
 	"github.com/golangci/golangci-lint/pkg/result"
 	"github.com/golangci/golangci-lint/pkg/result/processors"
 )
@@ -54,7 +59,10 @@ func wh(text string) string {
 	return color.GreenString(text)
 }
 
-const defaultTimeout = time.Minute
+const (
+	defaultTimeout  = time.Minute
+	defaultLockWait = 5 * time.Second
+)
 
 //nolint:funlen
 func initFlagSet(fs *pflag.FlagSet, cfg *config.Config, m *lintersdb.Manager, isFinalInit bool) {
@@ -80,6 +88,8 @@ func initFlagSet(fs *pflag.FlagSet, cfg *config.Config, m *lintersdb.Manager, is
 	fs.BoolVar(&oc.PrintIssuedLine, "print-issued-lines", true, wh("Print lines of code with issue"))
 	fs.BoolVar(&oc.PrintLinterName, "print-linter-name", true, wh("Print linter name in issue line"))
 	fs.BoolVar(&oc.UniqByLine, "uniq-by-line", true, wh("Make issues output unique by line"))
+	fs.BoolVar(&oc.ExplainFiltered, "explain-filtered", false,
+		wh("Explain why issues were filtered out: print every dropped issue with the processor and the rule which dropped it"))
 	fs.BoolVar(&oc.PrintWelcomeMessage, "print-welcome", false, wh("Print welcome message"))
 	hideFlag("print-welcome") // no longer used
 
@@ -100,6 +110,14 @@ func initFlagSet(fs *pflag.FlagSet, cfg *config.Config, m *lintersdb.Manager, is
 	fs.BoolVar(&rc.AnalyzeTests, "tests", true, wh("Analyze tests (*_test.go)"))
 	fs.BoolVar(&rc.PrintResourcesUsage, "print-resources-usage", false,
 		wh("Print avg and max memory usage of golangci-lint and total time"))
+	fs.StringVar(&rc.ProfileReportPath, "profile-report", "",
+		wh("Write JSON report with timings of linters, analyzers and packages, cache and memory stats to file `PATH`"))
+	fs.StringVar(&rc.TraceEventsPath, "trace-events", "",
+		wh("Write spans of packages loading, linters, analyzers, cache IO and processors "+
+			"in Chrome trace event format (chrome://tracing, Perfetto) to file `PATH`"))
+	fs.BoolVar(&rc.ShowProgress, "progress", false,
+		wh("Show progress of packages analysis and running linters: a live bar on a terminal, periodic lines otherwise"))
+	fs.StringVar(&rc.ProgressEventsPath, "progress-events", "", wh("Write progress events as JSON lines to file `PATH`"))
 	fs.StringVarP(&rc.Config, "config", "c", "", wh("Read config from file path `PATH`"))
 	fs.BoolVar(&rc.NoConfig, "no-config", false, wh("Don't read config"))
 	fs.StringSliceVar(&rc.SkipDirs, "skip-dirs", nil, wh("Regexps of directories to skip"))
@@ -109,6 +127,13 @@ func initFlagSet(fs *pflag.FlagSet, cfg *config.Config, m *lintersdb.Manager, is
 	const allowParallelDesc = "Allow multiple parallel golangci-lint instances running. " +
 		"If false (default) - golangci-lint acquires file lock on start."
 	fs.BoolVar(&rc.AllowParallelRunners, "allow-parallel-runners", false, wh(allowParallelDesc))
+	fs.DurationVar(&rc.LockWait, "lock-wait", defaultLockWait, wh(lockWaitDesc))
+	fs.BoolVar(&rc.AllowLinterFailures, "allow-linter-failures", false,
+		wh("Don't fail the run on a linter failure: report issues of other linters"))
+	fs.StringVar(&rc.MaxMemory, "max-memory", "",
+		wh("Memory limit like 4GiB: close to it packages are analyzed one by one and facts are spilled to disk"))
+	fs.StringVar(&rc.CacheBackend, "cache-backend", "",
+		wh("Shared cache to read through and write through: an http(s) URL or an absolute path of a directory"))
 
 	// Linters settings config
 	lsc := &cfg.LintersSettings
@@ -200,6 +225,10 @@ func initFlagSet(fs *pflag.FlagSet, cfg *config.Config, m *lintersdb.Manager, is
 		wh("Maximum issues count per one linter. Set to 0 to disable"))
 	fs.IntVar(&ic.MaxSameIssues, "max-same-issues", 3,
 		wh("Maximum count of issues with the same text. Set to 0 to disable"))
+	fs.BoolVar(&ic.MergeDuplicates, "merge-duplicates", true,
+		wh("Merge issues reported by different linters about the same problem into one issue"))
+	fs.StringSliceVar(&ic.MergeDuplicatesPrefer, "merge-duplicates-prefer", nil,
+		wh("Linters which issue is kept when merging duplicates, in order of preference"))
 
 	fs.BoolVarP(&ic.Diff, "new", "n", false,
 		wh("Show only new issues: if there are unstaged changes or untracked files, only those changes "+
@@ -210,9 +239,23 @@ func initFlagSet(fs *pflag.FlagSet, cfg *config.Config, m *lintersdb.Manager, is
 			"unstaged files before golangci-lint runs."))
 	fs.StringVar(&ic.DiffFromRevision, "new-from-rev", "",
 		wh("Show only new issues created after git revision `REV`"))
+	fs.StringVar(&ic.DiffFromMergeBase, "new-from-merge-base", "",
+		wh("Show only new issues created after the merge base of git revision `REV` and HEAD"))
 	fs.StringVar(&ic.DiffPatchFilePath, "new-from-patch", "",
 		wh("Show only new issues created in git patch with file path `PATH`"))
+	fs.StringVar(&ic.DiffGranularity, "new-granularity", config.DiffGranularityLine,
+		wh(fmt.Sprintf("Which issues are new: issues on changed lines, in changed hunks "+
+			"or in changed functions (%s)", strings.Join(config.DiffGranularities, "|"))))
 	fs.BoolVar(&ic.NeedFix, "fix", false, "Fix found issues (if it's supported by the linter)")
+
+	// Severity config
+	sc := &cfg.Severity
+	severityScale := strings.Join(result.SeverityScale, "|")
+	fs.StringVar(&sc.FailThreshold, "fail-on-severity", "",
+		wh(fmt.Sprintf("Exit with issues-exit-code only if issues with this or higher severity (%s) were found. "+
+			"By default any issue fails the run", severityScale)))
+	fs.StringVar(&sc.MinSeverity, "min-severity", "",
+		wh(fmt.Sprintf("Hide issues with lower severity than this one (%s)", severityScale)))
 }
 
 func (e *Executor) initRunConfiguration(cmd *cobra.Command) {
@@ -239,6 +282,8 @@ func (e *Executor) getConfigForCommandLine() (*config.Config, error) {
 	initRootFlagSet(fs, &cfg, true)
 
 	fs.Usage = func() {} // otherwise help text will be printed twice
+	// Flags of subcommands (e.g. cache trim --max-size) are checked by the main parsing.
+	fs.ParseErrorsWhitelist.UnknownFlags = true
 	if err := fs.Parse(os.Args); err != nil {
 		if err == pflag.ErrHelp {
 			return nil, err
@@ -256,8 +301,8 @@ func (e *Executor) initRun() {
 		Short: welcomeMessage,
 		Run:   e.executeRun,
 		PreRun: func(_ *cobra.Command, _ []string) {
-			if ok := e.acquireFileLock(); !ok {
-				e.log.Fatalf("Parallel golangci-lint is running")
+			if err := e.acquireFileLock(); err != nil {
+				e.log.Fatalf("%s", err)
 			}
 		},
 		PostRun: func(_ *cobra.Command, _ []string) {
@@ -312,23 +357,54 @@ func (e *Executor) runAnalysis(ctx context.Context, args []string) ([]result.Iss
 		e.reportData.AddLinter(lc.Name(), isEnabled, lc.EnabledByDefault)
 	}
 
-	lintCtx, err := e.contextLoader.Load(ctx, lintersToRun)
+	loadingDone := e.inFlight.Start("stages", "loading packages")
+	// The patch is read once for the packages filtering and the issues processing.
+	diff := processors.NewDiff(&e.cfg.Issues)
+	lintCtx, err := e.contextLoader.Load(ctx, lintersToRun, diff)
+	loadingDone()
 	if err != nil {
 		return nil, errors.Wrap(err, "context loading failed")
 	}
 	lintCtx.Log = e.log.Child("linters context")
+	lintCtx.InFlight = e.inFlight
+	lintCtx.Progress = e.progress
+
+	maxMemory, _ := e.cfg.Run.MaxMemoryBytes() // validated in runAndPrint
+	lintCtx.MemoryLimiter = load.NewMemoryLimiter(uint64(maxMemory))
+	defer func() {
+		if err := lintCtx.MemoryLimiter.Close(); err != nil {
+			e.log.Warnf("Failed to remove spilled facts: %s", err)
+		}
+	}()
+	analysisConcurrency := e.cfg.Run.Concurrency
+	if analysisConcurrency < 1 {
+		analysisConcurrency = runtime.GOMAXPROCS(-1)
+	}
+	lintCtx.AnalysisSem = make(chan struct{}, analysisConcurrency)
 
 	runner, err := lint.NewRunner(e.cfg, e.log.Child("runner"),
-		e.goenv, e.EnabledLintersSet, e.lineCache, e.DBManager, lintCtx.Packages)
+		e.goenv, e.EnabledLintersSet, e.lineCache, e.DBManager, lintCtx.Packages, diff, &e.reportData)
 	if err != nil {
 		return nil, err
 	}
 
+	// The shared cache backend is best-effort: its errors don't fail the run.
+	defer func() {
+		if err := lintCtx.PkgCache.FlushBackend(); err != nil {
+			e.log.Warnf("Cache backend failed, results aren't shared: %s", err)
+		}
+	}()
+
 	issues, err := runner.Run(ctx, lintersToRun, lintCtx)
 	if err != nil {
 		return nil, err
 	}
 
+	// Keys are needed to find entries of packages by `cache export`.
+	if err := e.saveUsedCacheKeys(lintCtx.PkgCache); err != nil {
+		e.log.Infof("Failed to save used cache keys: %s", err)
+	}
+
 	fixer := processors.NewFixer(e.cfg, e.log, e.fileCache)
 	return fixer.Process(issues), nil
 }
@@ -346,15 +422,51 @@ func (e *Executor) setOutputToDevNull() (savedStdout, savedStderr *os.File) {
 }
 
 func (e *Executor) setExitCodeIfIssuesFound(issues []result.Issue) {
-	if len(issues) != 0 {
+	// Issues hidden by limits are taken into account by the runner.
+	if e.reportData.FailThresholdReached {
 		e.exitCode = e.cfg.Run.ExitCodeIfIssuesFound
+		return
+	}
+
+	// Issues which failed to be fixed are returned by the fixer.
+	for i := range issues {
+		if issues[i].IsSeverityAtLeast(e.cfg.Severity.FailThreshold) {
+			e.exitCode = e.cfg.Run.ExitCodeIfIssuesFound
+			return
+		}
 	}
 }
 
+// initCacheRoots makes cache keys and cached paths relative to the module root,
+// GOROOT and the modules cache: a cache restored into a checkout at another path
+// (e.g. on CI) stays valid.
+func (e *Executor) initCacheRoots() {
+	moduleRoot := ""
+	if goMod := e.goenv.Get(goutil.EnvGoMod); goMod != "" && goMod != os.DevNull {
+		moduleRoot = filepath.Dir(goMod)
+	} else if wd, err := os.Getwd(); err == nil {
+		moduleRoot = wd
+	}
+
+	e.pkgCache.SetRoots(map[string]string{
+		"MODROOT":    moduleRoot,
+		"GOROOT":     e.goenv.Get(goutil.EnvGoRoot),
+		"GOMODCACHE": e.goenv.Get(goutil.EnvGoModCache),
+	})
+}
+
 func (e *Executor) runAndPrint(ctx context.Context, args []string) error {
 	if err := e.goenv.Discover(ctx); err != nil {
 		e.log.Warnf("Failed to discover go env: %s", err)
 	}
+	e.initCacheRoots()
+
+	// Progress is printed to the real stderr: detect a terminal before the redirection.
+	closeProgress, err := e.initProgress()
+	if err != nil {
+		return err
+	}
+	defer closeProgress()
 
 	if !logutils.HaveDebugTag("linters_output") {
 		// Don't allow linters and loader to print anything
@@ -365,7 +477,19 @@ func (e *Executor) runAndPrint(ctx context.Context, args []string) error {
 		}()
 	}
 
+	if _, err := e.cfg.Run.MaxMemoryBytes(); err != nil {
+		return errors.Wrap(err, "invalid run options")
+	}
+	if err := e.cfg.Issues.Validate(); err != nil {
+		return errors.Wrap(err, "invalid issues options")
+	}
+	if err := validateSeverityOptions(&e.cfg.Severity); err != nil {
+		return errors.Wrap(err, "invalid severity options")
+	}
+
+	e.progress.Start()
 	issues, err := e.runAnalysis(ctx, args)
+	e.progress.Stop() // don't mix progress with printed issues
 	if err != nil {
 		return err // XXX: don't loose type
 	}
@@ -381,11 +505,101 @@ func (e *Executor) runAndPrint(ctx context.Context, args []string) error {
 		return fmt.Errorf("can't print %d issues: %s", len(issues), err)
 	}
 
+	if e.reportData.Partial && e.cfg.Output.Format != config.OutFormatJSON {
+		// the JSON printer includes the Partial flag into the report
+		printPartialResultsMarker(e.reportData.Linters)
+	}
+
+	if e.cfg.Output.ExplainFiltered && e.cfg.Output.Format != config.OutFormatJSON {
+		// the JSON printer includes filtered issues into the report
+		printFilteredIssues(e.reportData.FilteredIssues)
+	}
+
 	e.fileCache.PrintStats(e.log)
 
 	return nil
 }
 
+// validateSeverityOptions checks severity names of the fail threshold and min severity.
+func validateSeverityOptions(cfg *config.Severity) error {
+	if err := validateOptionalSeverity(cfg.FailThreshold); err != nil {
+		return fmt.Errorf("invalid fail threshold: %v", err)
+	}
+	if err := validateOptionalSeverity(cfg.MinSeverity); err != nil {
+		return fmt.Errorf("invalid min severity: %v", err)
+	}
+	return nil
+}
+
+func validateOptionalSeverity(severity string) error {
+	if severity == "" {
+		return nil
+	}
+	if _, ok := result.SeverityLevel(severity); !ok {
+		return fmt.Errorf("unknown severity %q, valid severities: %s",
+			severity, strings.Join(result.SeverityScale, ", "))
+	}
+	return nil
+}
+
+func printPartialResultsMarker(linters []report.LinterData) {
+	var unfinished []string
+	for _, ld := range linters {
+		if ld.Unfinished {
+			unfinished = append(unfinished, ld.Name)
+		}
+	}
+
+	fmt.Fprintf(logutils.StdErr, "PARTIAL RESULTS: the timeout was exceeded, issues of these linters aren't reported: %s\n",
+		strings.Join(unfinished, ", "))
+}
+
+func printFilteredIssues(filteredIssues []report.FilteredIssue) {
+	for ind := range filteredIssues {
+		fi := &filteredIssues[ind]
+		how := fi.Processor
+		if fi.Reason != "" {
+			how += ": " + fi.Reason
+		}
+
+		fmt.Fprintf(logutils.StdErr, "Filtered out by %s\n  %s: %s (%s)\n",
+			how, fi.Issue.Pos, fi.Issue.Text, fi.Issue.FromLinter)
+	}
+}
+
+func (e *Executor) initProgress() (closeProgress func(), err error) {
+	closeProgress = func() {}
+	if !e.cfg.Run.ShowProgress && e.cfg.Run.ProgressEventsPath == "" {
+		return closeProgress, nil
+	}
+
+	var out io.Writer
+	isTTY := false
+	if e.cfg.Run.ShowProgress {
+		out = logutils.StdErr
+		if fi, statErr := os.Stderr.Stat(); statErr == nil {
+			isTTY = fi.Mode()&os.ModeCharDevice != 0
+		}
+	}
+
+	var events io.Writer
+	if e.cfg.Run.ProgressEventsPath != "" {
+		f, err := os.Create(e.cfg.Run.ProgressEventsPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "can't create progress events file")
+		}
+		closeProgress = func() {
+			if err := f.Close(); err != nil {
+				e.log.Warnf("Failed to close progress events file: %s", err)
+			}
+		}
+		events = f
+	}
+
+	e.progress = report.NewProgress(out, isTTY, events)
+	return closeProgress, nil
+}
+
 func (e *Executor) createPrinter() (printers.Printer, error) {
 	var p printers.Printer
 	format := e.cfg.Output.Format
@@ -414,7 +628,25 @@ func (e *Executor) createPrinter() (printers.Printer, error) {
 }
 
 func (e *Executor) executeRun(_ *cobra.Command, args []string) {
-	needTrackResources := e.cfg.Run.IsVerbose || e.cfg.Run.PrintResourcesUsage
+	if e.cfg.Run.ProfileReportPath != "" {
+		// XXX: this defer must be before waiting for resources tracking to have memory stats
+		defer func() {
+			if err := e.profile.Write(e.cfg.Run.ProfileReportPath); err != nil {
+				e.log.Errorf("Failed to write profile report: %s", err)
+			}
+		}()
+	}
+
+	if e.tracer != nil {
+		defer func() {
+			if err := e.tracer.Write(e.cfg.Run.TraceEventsPath); err != nil {
+				e.log.Errorf("Failed to write trace events: %s", err)
+			}
+		}()
+	}
+
+	needTrackResources := e.cfg.Run.IsVerbose || e.cfg.Run.PrintResourcesUsage || e.cfg.Run.ProfileReportPath != "" ||
+		e.cfg.Run.MaxMemory != ""
 	trackResourcesEndCh := make(chan struct{})
 	defer func() { // XXX: this defer must be before ctx.cancel defer
 		if needTrackResources { // wait until resource tracking finished to print properly
@@ -427,7 +659,8 @@ func (e *Executor) executeRun(_ *cobra.Command, args []string) {
 	defer cancel()
 
 	if needTrackResources {
-		go watchResources(ctx, trackResourcesEndCh, e.log, e.debugf)
+		maxMemory, _ := e.cfg.Run.MaxMemoryBytes() // the error is reported by runAndPrint
+		go watchResources(ctx, trackResourcesEndCh, e.log, e.debugf, e.profile, maxMemory)
 	}
 
 	if err := e.runAndPrint(ctx, args); err != nil {
@@ -457,6 +690,8 @@ func (e *Executor) setupExitCode(ctx context.Context) {
 	if ctx.Err() != nil {
 		e.exitCode = exitcodes.Timeout
 		e.log.Errorf("Timeout exceeded: try increasing it by passing --timeout option")
+		// Show what was running to decide whether to raise the timeout, disable a linter or skip a path.
+		fmt.Fprint(logutils.StdErr, e.inFlight.Sprint())
 		return
 	}
 
@@ -477,11 +712,12 @@ func (e *Executor) setupExitCode(ctx context.Context) {
 	}
 }
 
-func watchResources(ctx context.Context, done chan struct{}, logger logutils.Log, debugf logutils.DebugFunc) {
+func watchResources(ctx context.Context, done chan struct{}, logger logutils.Log, debugf logutils.DebugFunc,
+	profile *report.Profile, maxMemory int64) {
 	startedAt := time.Now()
 	debugf("Started tracking time")
 
-	var maxRSSMB, totalRSSMB float64
+	var maxRSSMB, totalRSSMB, maxHeapMB float64
 	var iterationsCount int
 
 	const intervalMS = 100
@@ -505,6 +741,9 @@ func watchResources(ctx context.Context, done chan struct{}, logger logutils.Log
 			maxRSSMB = rssMB
 		}
 		totalRSSMB += rssMB
+		if heapMB := float64(m.HeapInuse) / MB; heapMB > maxHeapMB {
+			maxHeapMB = heapMB
+		}
 		iterationsCount++
 	}
 
@@ -529,6 +768,20 @@ func watchResources(ctx context.Context, done chan struct{}, logger logutils.Log
 
 	logger.Infof("Memory: %d samples, avg is %.1fMB, max is %.1fMB",
 		iterationsCount, avgRSSMB, maxRSSMB)
+	if maxMemory != 0 {
+		maxMemoryMB := float64(maxMemory) / MB
+		if maxHeapMB > maxMemoryMB {
+			logger.Warnf("Peak heap usage %.1fMB exceeded max-memory %.1fMB", maxHeapMB, maxMemoryMB)
+		} else {
+			logger.Infof("Memory: peak heap usage is %.1fMB of max-memory %.1fMB", maxHeapMB, maxMemoryMB)
+		}
+	}
+	profile.SetMemory(report.MemoryStats{
+		Samples:   iterationsCount,
+		AvgRSSMB:  avgRSSMB,
+		MaxRSSMB:  maxRSSMB,
+		MaxHeapMB: maxHeapMB,
+	})
 	logger.Infof("Execution took %s", time.Since(startedAt))
 	close(done)
 }
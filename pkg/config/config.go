@@ -1,9 +1,15 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
 	"regexp"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -29,6 +35,18 @@ var OutFormats = []string{
 	OutFormatGithubActions,
 }
 
+const (
+	DiffGranularityLine     = "line"
+	DiffGranularityHunk     = "hunk"
+	DiffGranularityFunction = "function"
+)
+
+var DiffGranularities = []string{
+	DiffGranularityLine,
+	DiffGranularityHunk,
+	DiffGranularityFunction,
+}
+
 type ExcludePattern struct {
 	ID      string
 	Pattern string
@@ -127,6 +145,10 @@ type Run struct {
 	CPUProfilePath      string
 	MemProfilePath      string
 	TracePath           string
+	ProfileReportPath   string
+	TraceEventsPath     string
+	ShowProgress        bool `mapstructure:"progress"`
+	ProgressEventsPath  string
 	Concurrency         int
 	PrintResourcesUsage bool `mapstructure:"print-resources-usage"`
 
@@ -151,7 +173,45 @@ type Run struct {
 	SkipDirs           []string `mapstructure:"skip-dirs"`
 	UseDefaultSkipDirs bool     `mapstructure:"skip-dirs-use-default"`
 
-	AllowParallelRunners bool `mapstructure:"allow-parallel-runners"`
+	AllowParallelRunners bool          `mapstructure:"allow-parallel-runners"`
+	LockWait             time.Duration `mapstructure:"lock-wait"`
+	AllowLinterFailures  bool          `mapstructure:"allow-linter-failures"`
+
+	// MaxMemory is a size like "4GiB", see ParseSize
+	MaxMemory string `mapstructure:"max-memory"`
+
+	// CacheBackend is an http(s) URL or an absolute path of a shared cache, see cache.NewBackend
+	CacheBackend string `mapstructure:"cache-backend"`
+
+	// CacheMaxSize is a size like "2GiB", see ParseSize
+	CacheMaxSize string        `mapstructure:"cache-max-size"`
+	CacheMaxAge  time.Duration `mapstructure:"cache-max-age"`
+}
+
+// MaxMemoryBytes returns the parsed max-memory option, 0 means no limit.
+func (r *Run) MaxMemoryBytes() (int64, error) {
+	if r.MaxMemory == "" {
+		return 0, nil
+	}
+
+	size, err := ParseSize(r.MaxMemory)
+	if err != nil {
+		return 0, fmt.Errorf("invalid max-memory: %s", err)
+	}
+	return size, nil
+}
+
+// CacheMaxSizeBytes returns the parsed cache-max-size option, 0 means no limit.
+func (r *Run) CacheMaxSizeBytes() (int64, error) {
+	if r.CacheMaxSize == "" {
+		return 0, nil
+	}
+
+	size, err := ParseSize(r.CacheMaxSize)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cache-max-size: %s", err)
+	}
+	return size, nil
 }
 
 type LintersSettings struct {
@@ -245,6 +305,52 @@ type LintersSettings struct {
 	Exhaustive  ExhaustiveSettings
 
 	Custom map[string]CustomLinterSettings
+
+	// Timeouts are read from linters-settings.<linter>.timeout options:
+	// the option is common for all linters.
+	Timeouts map[string]time.Duration `mapstructure:"-"`
+}
+
+// Hash returns a hash of settings consumed by the linters: settings of a linter
+// are in the field named as the linter (e.g. Lll for lll) or in Custom.
+// Cached results of a linter are invalidated only when its own settings change.
+func (s *LintersSettings) Hash(linterNames ...string) (string, error) {
+	names := append([]string{}, linterNames...)
+	sort.Strings(names)
+
+	fields := map[string]reflect.Value{}
+	v := reflect.ValueOf(*s)
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if f.Tag.Get("mapstructure") == "-" {
+			continue
+		}
+		fields[strings.ToLower(f.Name)] = v.Field(i)
+	}
+
+	h := sha256.New()
+	for i, name := range names {
+		if i != 0 && names[i-1] == name {
+			continue
+		}
+
+		var settings interface{}
+		if f, ok := fields[name]; ok && name != "custom" {
+			settings = f.Interface()
+		} else if custom, ok := s.Custom[name]; ok {
+			settings = custom
+		} else {
+			continue // the linter has no settings
+		}
+
+		settingsBytes, err := json.Marshal(settings)
+		if err != nil {
+			return "", fmt.Errorf("failed to json marshal settings of %s: %s", name, err)
+		}
+		fmt.Fprintf(h, "%s=%s\n", name, settingsBytes)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
 }
 
 type GovetSettings struct {
@@ -399,10 +505,25 @@ var defaultLintersSettings = LintersSettings{
 	},
 }
 
+// Types of custom linters, see CustomLinterSettings.Type.
+const (
+	CustomLinterTypeGoPlugin   = "goplugin"
+	CustomLinterTypeExecutable = "executable"
+	CustomLinterTypeModule     = "module"
+)
+
 type CustomLinterSettings struct {
+	// Type is CustomLinterTypeGoPlugin (default) for a Go plugin *.so,
+	// CustomLinterTypeExecutable for an analysis tool executable, see goanalysis.ExternalTool,
+	// or CustomLinterTypeModule for a plugin compiled in by `golangci-lint custom`, see lintersdb.RegisterPlugin
+	Type        string
 	Path        string
 	Description string
 	OriginalURL string `mapstructure:"original-url"`
+
+	// Settings are passed to the linter: a Go plugin implementing AnalyzerPluginWithSettings
+	// gets them as is, otherwise they're analyzers flags like {"<analyzer>": {"<flag>": <value>}}
+	Settings interface{}
 }
 
 type Linters struct {
@@ -480,6 +601,30 @@ func (s *SeverityRule) Validate() error {
 	return s.BaseRule.Validate(severityRuleMinConditionsCount)
 }
 
+const limitRuleMinConditionsCount = 1
+
+// LimitRule overrides limits of issues count for issues matched by linters and path.
+// Unset limits aren't overridden, zero means no limit.
+type LimitRule struct {
+	Linters []string
+	Path    string
+
+	MaxIssuesPerLinter *int `mapstructure:"max-issues-per-linter"`
+	MaxSameIssues      *int `mapstructure:"max-same-issues"`
+	MaxIssuesPerFile   *int `mapstructure:"max-issues-per-file"`
+}
+
+func (l LimitRule) Validate() error {
+	// text and source conditions aren't supported: they don't define groups of issues
+	if err := (BaseRule{Linters: l.Linters, Path: l.Path}).Validate(limitRuleMinConditionsCount); err != nil {
+		return err
+	}
+	if l.MaxIssuesPerLinter == nil && l.MaxSameIssues == nil && l.MaxIssuesPerFile == nil {
+		return errors.New("at least one of (max-issues-per-linter, max-same-issues, max-issues-per-file) should be set")
+	}
+	return nil
+}
+
 type Issues struct {
 	IncludeDefaultExcludes []string      `mapstructure:"include"`
 	ExcludeCaseSensitive   bool          `mapstructure:"exclude-case-sensitive"`
@@ -487,20 +632,48 @@ type Issues struct {
 	ExcludeRules           []ExcludeRule `mapstructure:"exclude-rules"`
 	UseDefaultExcludes     bool          `mapstructure:"exclude-use-default"`
 
-	MaxIssuesPerLinter int `mapstructure:"max-issues-per-linter"`
-	MaxSameIssues      int `mapstructure:"max-same-issues"`
+	MaxIssuesPerLinter int         `mapstructure:"max-issues-per-linter"`
+	MaxSameIssues      int         `mapstructure:"max-same-issues"`
+	LimitRules         []LimitRule `mapstructure:"limits"`
+
+	MergeDuplicates       bool     `mapstructure:"merge-duplicates"`
+	MergeDuplicatesPrefer []string `mapstructure:"merge-duplicates-prefer"`
 
 	DiffFromRevision  string `mapstructure:"new-from-rev"`
+	DiffFromMergeBase string `mapstructure:"new-from-merge-base"`
 	DiffPatchFilePath string `mapstructure:"new-from-patch"`
 	Diff              bool   `mapstructure:"new"`
+	DiffGranularity   string `mapstructure:"new-granularity"`
 
 	NeedFix bool `mapstructure:"fix"`
 }
 
+func (i *Issues) Validate() error {
+	if i.DiffFromRevision != "" && i.DiffFromMergeBase != "" {
+		return errors.New("new-from-rev and new-from-merge-base can't be set together")
+	}
+
+	if i.DiffGranularity == "" {
+		return nil
+	}
+	for _, g := range DiffGranularities {
+		if i.DiffGranularity == g {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown new-granularity %q, valid granularities: %s",
+		i.DiffGranularity, strings.Join(DiffGranularities, ", "))
+}
+
 type Severity struct {
 	Default       string         `mapstructure:"default-severity"`
 	CaseSensitive bool           `mapstructure:"case-sensitive"`
 	Rules         []SeverityRule `mapstructure:"rules"`
+
+	// FailThreshold is the lowest severity of issues failing the run
+	FailThreshold string `mapstructure:"fail-threshold"`
+	// MinSeverity is the lowest severity of printed issues
+	MinSeverity string `mapstructure:"min-severity"`
 }
 
 type Config struct {
@@ -513,6 +686,7 @@ type Config struct {
 		PrintLinterName     bool `mapstructure:"print-linter-name"`
 		UniqByLine          bool `mapstructure:"uniq-by-line"`
 		PrintWelcomeMessage bool `mapstructure:"print-welcome"`
+		ExplainFiltered     bool `mapstructure:"explain-filtered"`
 	}
 
 	LintersSettings LintersSettings `mapstructure:"linters-settings"`
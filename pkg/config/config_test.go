@@ -0,0 +1,31 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintersSettingsHash(t *testing.T) {
+	var s LintersSettings
+	s.Lll.LineLength = 120
+
+	hash := func(names ...string) string {
+		h, err := s.Hash(names...)
+		require.NoError(t, err)
+		return h
+	}
+
+	lllHash, govetHash, bothHash := hash("lll"), hash("govet"), hash("govet", "lll")
+	assert.Equal(t, bothHash, hash("lll", "govet", "lll"))
+
+	s.Lll.LineLength = 100
+	assert.NotEqual(t, lllHash, hash("lll"))
+	assert.NotEqual(t, bothHash, hash("govet", "lll"))
+	assert.Equal(t, govetHash, hash("govet"), "govet cache must not be invalidated by lll settings")
+
+	customHash := hash("custom-linter")
+	s.Custom = map[string]CustomLinterSettings{"custom-linter": {Path: "/plugin.so"}}
+	assert.NotEqual(t, customHash, hash("custom-linter"))
+}
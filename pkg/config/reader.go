@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/spf13/viper"
@@ -75,6 +76,12 @@ func (r *FileReader) parseConfig() error {
 		return fmt.Errorf("can't unmarshal config by viper: %s", err)
 	}
 
+	timeouts, err := readLintersTimeouts()
+	if err != nil {
+		return fmt.Errorf("can't read linters timeouts: %s", err)
+	}
+	r.cfg.LintersSettings.Timeouts = timeouts
+
 	if err := r.validateConfig(); err != nil {
 		return fmt.Errorf("can't validate config: %s", err)
 	}
@@ -105,6 +112,18 @@ func (r *FileReader) validateConfig() error {
 		return errors.New("option run.tracepath in config isn't allowed")
 	}
 
+	if c.Run.ProfileReportPath != "" {
+		return errors.New("option run.profilereportpath in config isn't allowed")
+	}
+
+	if c.Run.TraceEventsPath != "" {
+		return errors.New("option run.traceeventspath in config isn't allowed")
+	}
+
+	if c.Run.ProgressEventsPath != "" {
+		return errors.New("option run.progresseventspath in config isn't allowed")
+	}
+
 	if c.Run.IsVerbose {
 		return errors.New("can't set run.verbose option with config: only on command-line")
 	}
@@ -113,6 +132,11 @@ func (r *FileReader) validateConfig() error {
 			return fmt.Errorf("error in exclude rule #%d: %v", i, err)
 		}
 	}
+	for i, rule := range c.Issues.LimitRules {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("error in limit rule #%d: %v", i, err)
+		}
+	}
 	if len(c.Severity.Rules) > 0 && c.Severity.Default == "" {
 		return errors.New("can't set severity rule option: no default severity defined")
 	}
@@ -121,12 +145,44 @@ func (r *FileReader) validateConfig() error {
 			return fmt.Errorf("error in severity rule #%d: %v", i, err)
 		}
 	}
+	if _, err := c.Run.MaxMemoryBytes(); err != nil {
+		return fmt.Errorf("error in run config: %v", err)
+	}
+	if _, err := c.Run.CacheMaxSizeBytes(); err != nil {
+		return fmt.Errorf("error in run config: %v", err)
+	}
+	if c.Run.CacheMaxAge < 0 {
+		return errors.New("error in run config: cache-max-age must be positive")
+	}
+	if err := c.Issues.Validate(); err != nil {
+		return fmt.Errorf("error in issues config: %v", err)
+	}
 	if err := c.LintersSettings.Govet.Validate(); err != nil {
 		return fmt.Errorf("error in govet config: %v", err)
 	}
 	return nil
 }
 
+// readLintersTimeouts reads linters-settings.<linter>.timeout options: settings
+// of every linter can have it, so it isn't a field of linters settings structs.
+func readLintersTimeouts() (map[string]time.Duration, error) {
+	timeouts := map[string]time.Duration{}
+	for name := range viper.GetStringMap("linters-settings") {
+		key := fmt.Sprintf("linters-settings.%s.timeout", name)
+		if !viper.IsSet(key) {
+			continue
+		}
+
+		timeout := viper.GetDuration(key)
+		if timeout <= 0 {
+			return nil, fmt.Errorf("invalid timeout %q of linter %s", viper.GetString(key), name)
+		}
+		timeouts[name] = timeout
+	}
+
+	return timeouts, nil
+}
+
 func getFirstPathArg() string {
 	args := os.Args
 
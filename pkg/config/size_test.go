@@ -0,0 +1,31 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSize(t *testing.T) {
+	cases := []struct {
+		in   string
+		want int64
+	}{
+		{"1024", 1024},
+		{"10B", 10},
+		{"4GiB", 4 << 30},
+		{"1.5 MiB", 3 << 19},
+		{"512MB", 512 * 1000 * 1000},
+		{"2KB", 2000},
+	}
+	for _, c := range cases {
+		got, err := ParseSize(c.in)
+		assert.NoError(t, err, c.in)
+		assert.Equal(t, c.want, got, c.in)
+	}
+
+	for _, in := range []string{"", "GiB", "-1GiB", "4XB"} {
+		_, err := ParseSize(in)
+		assert.Error(t, err, in)
+	}
+}
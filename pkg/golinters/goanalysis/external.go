@@ -0,0 +1,262 @@
+package goanalysis
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/unitchecker"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/golangci/golangci-lint/pkg/logutils"
+)
+
+// ExternalTool is an analysis tool executable run out of process by the protocol
+// of `go vet -vettool`: tools built with unitchecker.Main support it.
+// Unlike Go plugins, the tool doesn't have to be built with the same versions of Go
+// and dependencies as golangci-lint: it type-checks the package by export data
+// of its dependencies and reports diagnostics in JSON.
+type ExternalTool struct {
+	path     string
+	id       string   // the output of -V=full: it changes with the tool binary
+	flags    []string // analyzers flags from settings
+	analyzer *analysis.Analyzer
+}
+
+// NewExternalTool returns the tool with the settings of its analyzers
+// like {"<analyzer>": {"<flag>": <value>}}: they're passed as flags "-<analyzer>.<flag>=<value>".
+func NewExternalTool(name, path string, settings map[string]map[string]interface{}) (*ExternalTool, error) {
+	out, err := runTool(path, "-V=full")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get tool version")
+	}
+
+	flags, err := makeToolFlags(path, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &ExternalTool{path: path, id: strings.TrimSpace(string(out)), flags: flags}
+	t.analyzer = &analysis.Analyzer{
+		Name: externalAnalyzerName(name),
+		Doc:  fmt.Sprintf("runs the analysis tool %s", path),
+		Run: func(*analysis.Pass) (interface{}, error) {
+			return nil, fmt.Errorf("analysis tool %s can be run only by the goanalysis runner", path)
+		},
+	}
+	return t, nil
+}
+
+// NewExternalLinter returns the linter running the tool. The tool parses and type-checks
+// packages itself: the linter needs only syntax to map positions of diagnostics.
+func NewExternalLinter(name, desc string, tool *ExternalTool) *Linter {
+	lnt := NewLinter(name, desc, []*analysis.Analyzer{tool.analyzer}, nil).WithLoadMode(LoadModeSyntax)
+	lnt.externalTool = tool
+	return lnt
+}
+
+func runTool(path string, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command(path, args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("analysis tool %s failed: %s: %s", path, err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// makeToolFlags checks the settings by flags the tool describes by -flags.
+func makeToolFlags(path string, settings map[string]map[string]interface{}) ([]string, error) {
+	if len(settings) == 0 {
+		return nil, nil
+	}
+
+	out, err := runTool(path, "-flags")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get tool flags")
+	}
+	var toolFlags []struct {
+		Name string
+	}
+	if err = json.Unmarshal(out, &toolFlags); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse flags of analysis tool %s", path)
+	}
+	validFlags := map[string]bool{}
+	for _, f := range toolFlags {
+		validFlags[f.Name] = true
+	}
+
+	var flags []string
+	for analyzerName, analyzerSettings := range settings {
+		for k, v := range analyzerSettings {
+			name := analyzerName + "." + k
+			if !validFlags[name] {
+				return nil, fmt.Errorf("analysis tool %s doesn't have setting %q of analyzer %s", path, k, analyzerName)
+			}
+			flags = append(flags, fmt.Sprintf("-%s=%s", name, valueToString(v)))
+		}
+	}
+	sort.Strings(flags)
+	return flags, nil
+}
+
+// externalAnalyzerName makes a valid analyzer name from the linter name.
+func externalAnalyzerName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// run runs the tool on the package like `go vet -vettool` does and returns its diagnostics.
+// Facts of dependencies aren't passed to the tool: it's run only on analyzed packages.
+func (t *ExternalTool) run(pkg *packages.Package, log logutils.Log) ([]analysis.Diagnostic, error) {
+	dir, err := ioutil.TempDir("", "golangci-lint-tool-")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make temp dir")
+	}
+	defer os.RemoveAll(dir)
+
+	cfgFile := filepath.Join(dir, "unit.cfg")
+	cfgData, err := json.Marshal(makeUnitConfig(pkg, filepath.Join(dir, "unit.vetx")))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to json marshal tool config")
+	}
+	if err = ioutil.WriteFile(cfgFile, cfgData, 0600); err != nil {
+		return nil, errors.Wrap(err, "failed to write tool config")
+	}
+
+	args := append(append([]string{"-json"}, t.flags...), cfgFile)
+	out, err := runTool(t.path, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	// The output is {"<package ID>": {"<analyzer>": [<diagnostic>...] or {"error": "<error>"}}}.
+	var tree map[string]map[string]json.RawMessage
+	if err = json.Unmarshal(out, &tree); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse output of analysis tool %s", t.path)
+	}
+
+	results := tree[pkg.ID]
+	names := make([]string, 0, len(results))
+	for name := range results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var diags []analysis.Diagnostic
+	for _, name := range names {
+		var toolErr struct {
+			Err string `json:"error"`
+		}
+		if json.Unmarshal(results[name], &toolErr) == nil && toolErr.Err != "" {
+			return nil, fmt.Errorf("analyzer %s of tool %s failed: %s", name, t.path, toolErr.Err)
+		}
+
+		var toolDiags []struct {
+			Posn    string `json:"posn"`
+			Message string `json:"message"`
+		}
+		if err = json.Unmarshal(results[name], &toolDiags); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse diagnostics of analyzer %s of tool %s", name, t.path)
+		}
+
+		for _, d := range toolDiags {
+			pos, err := findPos(pkg, d.Posn)
+			if err != nil {
+				log.Warnf("Skipping diagnostic %q of analyzer %s of tool %s: %s", d.Message, name, t.path, err)
+				continue
+			}
+			diags = append(diags, analysis.Diagnostic{Pos: pos, Category: name, Message: d.Message})
+		}
+	}
+	return diags, nil
+}
+
+func makeUnitConfig(pkg *packages.Package, vetxOutput string) *unitchecker.Config {
+	cfg := &unitchecker.Config{
+		ID:          pkg.ID,
+		Compiler:    "gc",
+		ImportPath:  pkg.PkgPath,
+		GoFiles:     pkg.CompiledGoFiles,
+		NonGoFiles:  pkg.OtherFiles,
+		ImportMap:   map[string]string{},
+		PackageFile: map[string]string{},
+		Standard:    map[string]bool{},
+		PackageVetx: map[string]string{},
+		VetxOutput:  vetxOutput,
+	}
+	if len(pkg.GoFiles) != 0 {
+		cfg.Dir = filepath.Dir(pkg.GoFiles[0])
+	}
+
+	for path, imp := range pkg.Imports {
+		cfg.ImportMap[path] = imp.PkgPath
+	}
+
+	// Export data of direct dependencies can refer to indirect ones.
+	var visit func(pkg *packages.Package)
+	visit = func(pkg *packages.Package) {
+		for _, imp := range pkg.Imports {
+			if _, ok := cfg.PackageFile[imp.PkgPath]; ok {
+				continue
+			}
+			cfg.PackageFile[imp.PkgPath] = imp.ExportFile
+			visit(imp)
+		}
+	}
+	visit(pkg)
+	return cfg
+}
+
+// findPos finds the position "file:line[:col]" in the parsed package files.
+func findPos(pkg *packages.Package, posn string) (token.Pos, error) {
+	parts := strings.Split(posn, ":")
+	if len(parts) < 2 {
+		return token.NoPos, fmt.Errorf("bad position %q", posn)
+	}
+
+	col := 0
+	if n, err := strconv.Atoi(parts[len(parts)-1]); err == nil && len(parts) >= 3 {
+		if _, err := strconv.Atoi(parts[len(parts)-2]); err == nil {
+			col = n
+			parts = parts[:len(parts)-1]
+		}
+	}
+	line, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil {
+		return token.NoPos, fmt.Errorf("bad position %q", posn)
+	}
+	fileName := strings.Join(parts[:len(parts)-1], ":")
+
+	for _, f := range pkg.Syntax {
+		tf := pkg.Fset.File(f.Pos())
+		if tf == nil || tf.Name() != fileName {
+			continue
+		}
+		if line < 1 || line > tf.LineCount() {
+			return token.NoPos, fmt.Errorf("line of position %q is out of file", posn)
+		}
+
+		pos := tf.LineStart(line)
+		if col > 1 && tf.Offset(pos)+col-1 <= tf.Size() {
+			pos += token.Pos(col - 1)
+		}
+		return pos, nil
+	}
+	return token.NoPos, fmt.Errorf("file of position %q isn't parsed", posn)
+}
@@ -0,0 +1,48 @@
+package goanalysis
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/tools/go/packages"
+)
+
+func TestFindPos(t *testing.T) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "/src/p/f.go", "package p\n\nfunc f() {}\n", 0)
+	assert.NoError(t, err)
+	pkg := &packages.Package{Fset: fset, Syntax: []*ast.File{f}}
+
+	cases := []struct {
+		posn       string
+		line, col  int
+		shouldFail bool
+	}{
+		{posn: "/src/p/f.go:3:6", line: 3, col: 6},
+		{posn: "/src/p/f.go:3", line: 3, col: 1},
+		{posn: "/src/p/f.go:4:1", shouldFail: true},
+		{posn: "/src/p/g.go:1:1", shouldFail: true},
+		{posn: "f.go", shouldFail: true},
+	}
+
+	for _, c := range cases {
+		pos, err := findPos(pkg, c.posn)
+		if c.shouldFail {
+			assert.Error(t, err, c.posn)
+			continue
+		}
+
+		assert.NoError(t, err, c.posn)
+		p := fset.Position(pos)
+		assert.Equal(t, "/src/p/f.go", p.Filename, c.posn)
+		assert.Equal(t, c.line, p.Line, c.posn)
+		assert.Equal(t, c.col, p.Column, c.posn)
+	}
+}
+
+func TestExternalAnalyzerName(t *testing.T) {
+	assert.Equal(t, "my_linter_2", externalAnalyzerName("my-linter.2"))
+}
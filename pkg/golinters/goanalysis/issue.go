@@ -23,6 +23,8 @@ func NewIssue(i *result.Issue, pass *analysis.Pass) Issue {
 type EncodingIssue struct {
 	FromLinter           string
 	Text                 string
+	Severity             string
+	Confidence           string
 	Pos                  token.Position
 	LineRange            *result.Range
 	Replacement          *result.Replacement
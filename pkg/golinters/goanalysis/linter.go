@@ -16,6 +16,7 @@ import (
 	"golang.org/x/tools/go/packages"
 
 	"github.com/golangci/golangci-lint/internal/pkgcache"
+	"github.com/golangci/golangci-lint/pkg/config"
 	"github.com/golangci/golangci-lint/pkg/lint/linter"
 	"github.com/golangci/golangci-lint/pkg/logutils"
 	libpackages "github.com/golangci/golangci-lint/pkg/packages"
@@ -59,9 +60,11 @@ type Linter struct {
 	cfg                     map[string]map[string]interface{}
 	issuesReporter          func(*linter.Context) []Issue
 	contextSetter           func(*linter.Context)
+	severityGetter          func(*analysis.Analyzer) string
 	loadMode                LoadMode
 	needUseOriginalPackages bool
 	isTypecheckModeOn       bool
+	externalTool            *ExternalTool // see NewExternalLinter
 }
 
 func NewLinter(name, desc string, analyzers []*analysis.Analyzer, cfg map[string]map[string]interface{}) *Linter {
@@ -90,6 +93,12 @@ func (lnt *Linter) WithIssuesReporter(r func(*linter.Context) []Issue) *Linter {
 	return lnt
 }
 
+// WithSeverity sets the function returning the severity of diagnostics of the given analyzer.
+func (lnt *Linter) WithSeverity(getter func(*analysis.Analyzer) string) *Linter {
+	lnt.severityGetter = getter
+	return lnt
+}
+
 func (lnt *Linter) WithContextSetter(cs func(*linter.Context)) *Linter {
 	lnt.contextSetter = cs
 	return lnt
@@ -215,7 +224,7 @@ func buildIssuesFromErrorsForTypecheckMode(errs []error, lintCtx *linter.Context
 	return issues, nil
 }
 
-func buildIssues(diags []Diagnostic, linterNameBuilder func(diag *Diagnostic) string) []result.Issue {
+func buildIssues(diags []Diagnostic, linterNameBuilder, severityBuilder func(diag *Diagnostic) string) []result.Issue {
 	var issues []result.Issue
 	for i := range diags {
 		diag := &diags[i]
@@ -229,6 +238,7 @@ func buildIssues(diags []Diagnostic, linterNameBuilder func(diag *Diagnostic) st
 		issues = append(issues, result.Issue{
 			FromLinter: linterName,
 			Text:       text,
+			Severity:   severityBuilder(diag),
 			Pos:        diag.Position,
 			Pkg:        diag.Pkg,
 		})
@@ -260,10 +270,33 @@ func (lnt *Linter) getLinterNameForDiagnostic(*Diagnostic) string {
 	return lnt.name
 }
 
+func (lnt *Linter) getLinterNameForAnalyzer(a *analysis.Analyzer) string {
+	for _, la := range lnt.analyzers {
+		if la == a {
+			return lnt.name
+		}
+	}
+	return "" // required analyzer, e.g. inspect
+}
+
+func (lnt *Linter) getSeverityForDiagnostic(diag *Diagnostic) string {
+	if lnt.severityGetter == nil {
+		return ""
+	}
+	return lnt.severityGetter(diag.Analyzer)
+}
+
 func (lnt *Linter) getAnalyzers() []*analysis.Analyzer {
 	return lnt.analyzers
 }
 
+func (lnt *Linter) getExternalTool(a *analysis.Analyzer) *ExternalTool {
+	if lnt.externalTool != nil && lnt.externalTool.analyzer == a {
+		return lnt.externalTool
+	}
+	return nil
+}
+
 func (lnt *Linter) useOriginalPackages() bool {
 	return lnt.needUseOriginalPackages
 }
@@ -286,19 +319,64 @@ func (lnt *Linter) getLoadMode() LoadMode {
 type runAnalyzersConfig interface {
 	getName() string
 	getLinterNameForDiagnostic(*Diagnostic) string
+	getLinterNameForAnalyzer(*analysis.Analyzer) string
+	getSeverityForDiagnostic(*Diagnostic) string
 	getAnalyzers() []*analysis.Analyzer
 	useOriginalPackages() bool
 	isTypecheckMode() bool
 	reportIssues(*linter.Context) []Issue
 	getLoadMode() LoadMode
+	getExternalTool(*analysis.Analyzer) *ExternalTool
+}
+
+func getIssuesCacheKey(analyzers []*analysis.Analyzer, settingsHash string) string {
+	return "lint/result:" + analyzersHashID(analyzers) + ":" + settingsHash
+}
+
+// issuesCacheGroup is a linter with its analyzers: its issues are cached separately
+// to not invalidate cached issues of all linters of the metalinter on settings change.
+type issuesCacheGroup struct {
+	linterName    string
+	analyzers     []*analysis.Analyzer
+	settingsHash  string // see LintersSettings.Hash
+	lintResKey    string
+	pkgsFromCache map[*packages.Package]bool
 }
 
-func getIssuesCacheKey(analyzers []*analysis.Analyzer) string {
-	return "lint/result:" + analyzersHashID(analyzers)
+func getIssuesCacheGroups(cfg runAnalyzersConfig, lintCtx *linter.Context) ([]*issuesCacheGroup, error) {
+	var settings config.LintersSettings
+	if lintCtx.Cfg != nil {
+		settings = lintCtx.Cfg.LintersSettings
+	}
+
+	var groups []*issuesCacheGroup
+	groupByLinter := map[string]*issuesCacheGroup{}
+	for _, a := range cfg.getAnalyzers() {
+		name := cfg.getLinterNameForAnalyzer(a)
+		g := groupByLinter[name]
+		if g == nil {
+			hash, err := settings.Hash(name)
+			if err != nil {
+				return nil, err
+			}
+			if tool := cfg.getExternalTool(a); tool != nil {
+				hash += ":" + tool.id // results change with the tool binary
+			}
+			g = &issuesCacheGroup{linterName: name, settingsHash: hash}
+			groupByLinter[name] = g
+			groups = append(groups, g)
+		}
+		g.analyzers = append(g.analyzers, a)
+	}
+
+	for _, g := range groups {
+		g.lintResKey = getIssuesCacheKey(g.analyzers, g.settingsHash)
+	}
+	return groups, nil
 }
 
 func saveIssuesToCache(allPkgs []*packages.Package, pkgsFromCache map[*packages.Package]bool,
-	issues []result.Issue, lintCtx *linter.Context, analyzers []*analysis.Analyzer) {
+	issues []result.Issue, lintCtx *linter.Context, lintResKey string) {
 	startedAt := time.Now()
 	perPkgIssues := map[*packages.Package][]result.Issue{}
 	for ind := range issues {
@@ -307,7 +385,6 @@ func saveIssuesToCache(allPkgs []*packages.Package, pkgsFromCache map[*packages.
 	}
 
 	savedIssuesCount := int32(0)
-	lintResKey := getIssuesCacheKey(analyzers)
 
 	workerCount := runtime.GOMAXPROCS(-1)
 	var wg sync.WaitGroup
@@ -322,10 +399,14 @@ func saveIssuesToCache(allPkgs []*packages.Package, pkgsFromCache map[*packages.
 				encodedIssues := make([]EncodingIssue, 0, len(pkgIssues))
 				for ind := range pkgIssues {
 					i := &pkgIssues[ind]
+					pos := i.Pos
+					pos.Filename = lintCtx.PkgCache.RelPath(pos.Filename)
 					encodedIssues = append(encodedIssues, EncodingIssue{
 						FromLinter:           i.FromLinter,
-						Text:                 i.Text,
-						Pos:                  i.Pos,
+						Text:                 lintCtx.PkgCache.RelText(i.Text),
+						Severity:             i.Severity,
+						Confidence:           i.Confidence,
+						Pos:                  pos,
 						LineRange:            i.LineRange,
 						Replacement:          i.Replacement,
 						ExpectNoLint:         i.ExpectNoLint,
@@ -334,7 +415,8 @@ func saveIssuesToCache(allPkgs []*packages.Package, pkgsFromCache map[*packages.
 				}
 
 				atomic.AddInt32(&savedIssuesCount, int32(len(encodedIssues)))
-				if err := lintCtx.PkgCache.Put(pkg, pkgcache.HashModeNeedAllDeps, lintResKey, encodedIssues); err != nil {
+				err := lintCtx.PkgCache.Put(pkg, pkgcache.HashModeNeedAllDeps, pkgcache.KindLintResults, lintResKey, encodedIssues)
+				if err != nil {
 					lintCtx.Log.Infof("Failed to save package %s issues (%d) to cache: %s", pkg, len(pkgIssues), err)
 				} else {
 					issuesCacheDebugf("Saved package %s issues (%d) to cache", pkg, len(pkgIssues))
@@ -358,10 +440,9 @@ func saveIssuesToCache(allPkgs []*packages.Package, pkgsFromCache map[*packages.
 
 //nolint:gocritic
 func loadIssuesFromCache(pkgs []*packages.Package, lintCtx *linter.Context,
-	analyzers []*analysis.Analyzer) ([]result.Issue, map[*packages.Package]bool) {
+	lintResKey string) ([]result.Issue, map[*packages.Package]bool) {
 	startedAt := time.Now()
 
-	lintResKey := getIssuesCacheKey(analyzers)
 	type cacheRes struct {
 		issues  []result.Issue
 		loadErr error
@@ -382,6 +463,7 @@ func loadIssuesFromCache(pkgs []*packages.Package, lintCtx *linter.Context,
 			for pkg := range pkgCh {
 				var pkgIssues []EncodingIssue
 				err := lintCtx.PkgCache.Get(pkg, pkgcache.HashModeNeedAllDeps, lintResKey, &pkgIssues)
+				lintCtx.Profile.AddCacheLookup("lint results", err == nil)
 				cacheRes := pkgToCacheRes[pkg]
 				cacheRes.loadErr = err
 				if err != nil {
@@ -393,10 +475,14 @@ func loadIssuesFromCache(pkgs []*packages.Package, lintCtx *linter.Context,
 
 				issues := make([]result.Issue, 0, len(pkgIssues))
 				for _, i := range pkgIssues {
+					pos := i.Pos
+					pos.Filename = lintCtx.PkgCache.AbsPath(pos.Filename)
 					issues = append(issues, result.Issue{
 						FromLinter:           i.FromLinter,
-						Text:                 i.Text,
-						Pos:                  i.Pos,
+						Text:                 lintCtx.PkgCache.AbsText(i.Text),
+						Severity:             i.Severity,
+						Confidence:           i.Confidence,
+						Pos:                  pos,
 						LineRange:            i.LineRange,
 						Replacement:          i.Replacement,
 						Pkg:                  pkg,
@@ -433,35 +519,142 @@ func loadIssuesFromCache(pkgs []*packages.Package, lintCtx *linter.Context,
 	return issues, pkgsFromCache
 }
 
-func runAnalyzers(cfg runAnalyzersConfig, lintCtx *linter.Context) ([]result.Issue, error) {
+// loadGroupsIssuesFromCache returns cached issues, packages to analyze and groups of linters to run:
+// linters with all packages in the cache aren't run, other linters analyze packages
+// missing in the cache of any of them.
+func loadGroupsIssuesFromCache(pkgs []*packages.Package, lintCtx *linter.Context,
+	groups []*issuesCacheGroup) ([]result.Issue, []*packages.Package, []*issuesCacheGroup) {
+	issuesByGroup := map[*issuesCacheGroup][]result.Issue{}
+	for _, g := range groups {
+		issuesByGroup[g], g.pkgsFromCache = loadIssuesFromCache(pkgs, lintCtx, g.lintResKey)
+	}
+
+	return mergeGroupsCachedIssues(pkgs, groups, issuesByGroup)
+}
+
+// mergeGroupsCachedIssues selects groups to run and packages they analyze. A cached issue
+// is dropped only if its own group analyzes the package again: other groups don't re-report it.
+func mergeGroupsCachedIssues(pkgs []*packages.Package, groups []*issuesCacheGroup,
+	issuesByGroup map[*issuesCacheGroup][]result.Issue) ([]result.Issue, []*packages.Package, []*issuesCacheGroup) {
+	var groupsToRun []*issuesCacheGroup
+	willRun := map[*issuesCacheGroup]bool{}
+	for _, g := range groups {
+		if len(g.pkgsFromCache) != len(pkgs) {
+			groupsToRun = append(groupsToRun, g)
+			willRun[g] = true
+		}
+	}
+
+	needAnalyze := map[*packages.Package]bool{}
+	var pkgsToAnalyze []*packages.Package
+	for _, pkg := range pkgs {
+		for _, g := range groupsToRun {
+			if !g.pkgsFromCache[pkg] {
+				needAnalyze[pkg] = true
+				pkgsToAnalyze = append(pkgsToAnalyze, pkg)
+				break
+			}
+		}
+	}
+
+	var issues []result.Issue
+	for _, g := range groups {
+		for _, i := range issuesByGroup[g] {
+			// Analyzers of groups to run analyze all packages to analyze and report their issues again.
+			if willRun[g] && needAnalyze[i.Pkg] {
+				continue
+			}
+			issues = append(issues, i)
+		}
+	}
+
+	return issues, pkgsToAnalyze, groupsToRun
+}
+
+func saveGroupsIssuesToCache(analyzedPkgs []*packages.Package, issues []result.Issue, lintCtx *linter.Context,
+	groups []*issuesCacheGroup) {
+	if len(groups) == 0 {
+		return
+	}
+
+	analyzed := map[*packages.Package]bool{}
+	for _, pkg := range analyzedPkgs {
+		analyzed[pkg] = true
+	}
+
+	groupByLinter := map[string]*issuesCacheGroup{}
+	for _, g := range groups {
+		groupByLinter[g.linterName] = g
+	}
+
+	issuesByGroup := map[*issuesCacheGroup][]result.Issue{}
+	for _, i := range issues {
+		if !analyzed[i.Pkg] {
+			continue // the issue is from the cache
+		}
+
+		g := groupByLinter[i.FromLinter]
+		if g == nil {
+			// Linters report issues with their names, it can be different only for a single linter.
+			g = groups[0]
+		}
+		issuesByGroup[g] = append(issuesByGroup[g], i)
+	}
+
+	for _, g := range groups {
+		saveIssuesToCache(analyzedPkgs, nil, issuesByGroup[g], lintCtx, g.lintResKey)
+	}
+}
+
+func runAnalyzers(ctx context.Context, cfg runAnalyzersConfig, lintCtx *linter.Context) ([]result.Issue, error) {
 	log := lintCtx.Log.Child("goanalysis")
 	sw := timeutils.NewStopwatch("analyzers", log)
 
 	const stagesToPrint = 10
 	defer sw.PrintTopStages(stagesToPrint)
 
-	runner := newRunner(cfg.getName(), log, lintCtx.PkgCache, lintCtx.LoadGuard, cfg.getLoadMode(), sw)
+	lintCtx.InFlight.AddStopwatch(fmt.Sprintf("analyzers of %s", cfg.getName()), sw)
+	lintCtx.Profile.AddAnalyzersStopwatch(cfg.getName(), sw)
+
+	groups, err := getIssuesCacheGroups(cfg, lintCtx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to hash linters settings")
+	}
 
 	pkgs := lintCtx.Packages
 	if cfg.useOriginalPackages() {
 		pkgs = lintCtx.OriginalPackages
 	}
 
-	issues, pkgsFromCache := loadIssuesFromCache(pkgs, lintCtx, cfg.getAnalyzers())
-	var pkgsToAnalyze []*packages.Package
-	for _, pkg := range pkgs {
-		if !pkgsFromCache[pkg] {
-			pkgsToAnalyze = append(pkgsToAnalyze, pkg)
+	issues, pkgsToAnalyze, groupsToRun := loadGroupsIssuesFromCache(pkgs, lintCtx, groups)
+	lintCtx.Progress.AddPackages(cfg.getName(), len(pkgs), len(pkgs)-len(pkgsToAnalyze))
+
+	settingsHashes := map[*analysis.Analyzer]string{}
+	var analyzersToRun []*analysis.Analyzer
+	for _, g := range groupsToRun {
+		for _, a := range g.analyzers {
+			settingsHashes[a] = g.settingsHash
 		}
+		analyzersToRun = append(analyzersToRun, g.analyzers...)
 	}
 
-	diags, errs, passToPkg := runner.run(cfg.getAnalyzers(), pkgsToAnalyze)
+	runner := newRunner(cfg.getName(), log, lintCtx, cfg.getLoadMode(), sw, settingsHashes)
+	for _, a := range analyzersToRun {
+		if tool := cfg.getExternalTool(a); tool != nil {
+			runner.externalTools[a] = tool
+		}
+	}
+	diags, errs, passToPkg := runner.run(ctx, analyzersToRun, pkgsToAnalyze)
+	if err := ctx.Err(); err != nil {
+		// Analysis of some packages was skipped: don't report these errors and don't cache the results.
+		return nil, err
+	}
 
 	defer func() {
 		if len(errs) == 0 {
 			// If we try to save to cache even if we have compilation errors
 			// we won't see them on repeated runs.
-			saveIssuesToCache(pkgs, pkgsFromCache, issues, lintCtx, cfg.getAnalyzers())
+			saveGroupsIssuesToCache(pkgsToAnalyze, issues, lintCtx, groupsToRun)
 		}
 	}()
 
@@ -475,7 +668,7 @@ func runAnalyzers(cfg runAnalyzersConfig, lintCtx *linter.Context) ([]result.Iss
 			}
 			retIssues = append(retIssues, *issue)
 		}
-		retIssues = append(retIssues, buildIssues(diags, cfg.getLinterNameForDiagnostic)...)
+		retIssues = append(retIssues, buildIssues(diags, cfg.getLinterNameForDiagnostic, cfg.getSeverityForDiagnostic)...)
 		return retIssues
 	}
 
@@ -505,7 +698,7 @@ func (lnt *Linter) Run(ctx context.Context, lintCtx *linter.Context) ([]result.I
 		return nil, err
 	}
 
-	return runAnalyzers(lnt, lintCtx)
+	return runAnalyzers(ctx, lnt, lintCtx)
 }
 
 func analyzersHashID(analyzers []*analysis.Analyzer) string {
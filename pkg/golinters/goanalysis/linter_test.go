@@ -6,6 +6,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/tools/go/packages"
+
+	"github.com/golangci/golangci-lint/pkg/result"
 )
 
 func TestParseError(t *testing.T) {
@@ -46,3 +48,66 @@ func TestParseError(t *testing.T) {
 		assert.Equal(t, "msg", i.Text)
 	}
 }
+
+func TestMergeGroupsCachedIssues(t *testing.T) {
+	pkg1 := &packages.Package{PkgPath: "p1"}
+	pkg2 := &packages.Package{PkgPath: "p2"}
+	pkgs := []*packages.Package{pkg1, pkg2}
+
+	cached := &issuesCacheGroup{
+		linterName:    "govet",
+		pkgsFromCache: map[*packages.Package]bool{pkg1: true, pkg2: true},
+	}
+	partial := &issuesCacheGroup{
+		linterName:    "lll",
+		pkgsFromCache: map[*packages.Package]bool{pkg1: true},
+	}
+	issuesByGroup := map[*issuesCacheGroup][]result.Issue{
+		cached: {
+			{FromLinter: "govet", Text: "a", Pkg: pkg1},
+			{FromLinter: "govet", Text: "b", Pkg: pkg2},
+		},
+		partial: {
+			{FromLinter: "lll", Text: "c", Pkg: pkg1},
+		},
+	}
+
+	issues, pkgsToAnalyze, groupsToRun := mergeGroupsCachedIssues(pkgs,
+		[]*issuesCacheGroup{cached, partial}, issuesByGroup)
+
+	assert.Equal(t, []*issuesCacheGroup{partial}, groupsToRun)
+	assert.Equal(t, []*packages.Package{pkg2}, pkgsToAnalyze)
+
+	var texts []string
+	for _, i := range issues {
+		texts = append(texts, i.Text)
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, texts)
+}
+
+func TestMergeGroupsCachedIssuesReanalyzedByOwnGroup(t *testing.T) {
+	pkg1 := &packages.Package{PkgPath: "p1"}
+	pkg2 := &packages.Package{PkgPath: "p2"}
+	pkgs := []*packages.Package{pkg1, pkg2}
+
+	missingPkg1 := &issuesCacheGroup{
+		linterName:    "lll",
+		pkgsFromCache: map[*packages.Package]bool{pkg2: true},
+	}
+	missingPkg2 := &issuesCacheGroup{
+		linterName:    "errcheck",
+		pkgsFromCache: map[*packages.Package]bool{pkg1: true},
+	}
+	issuesByGroup := map[*issuesCacheGroup][]result.Issue{
+		missingPkg1: {{FromLinter: "lll", Text: "a", Pkg: pkg2}},
+		missingPkg2: {{FromLinter: "errcheck", Text: "b", Pkg: pkg1}},
+	}
+
+	issues, pkgsToAnalyze, groupsToRun := mergeGroupsCachedIssues(pkgs,
+		[]*issuesCacheGroup{missingPkg1, missingPkg2}, issuesByGroup)
+
+	// Both groups run on both packages and report all issues again.
+	assert.Len(t, groupsToRun, 2)
+	assert.Equal(t, pkgs, pkgsToAnalyze)
+	assert.Empty(t, issues)
+}
@@ -0,0 +1,198 @@
+package load
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	memoryCheckInterval = 50 * time.Millisecond
+
+	// Close to the limit packages are analyzed one by one, facts are spilled to disk
+	// and analyzed packages are reloaded from export data.
+	nearLimitPercent = 80
+)
+
+// MemoryLimiter bounds memory usage of go/analysis runners: all linters share it.
+// The usage is the Go heap in use. A nil *MemoryLimiter doesn't limit anything.
+type MemoryLimiter struct {
+	limit uint64
+
+	// number of packages being analyzed by all runners
+	active int32
+
+	mu        sync.Mutex
+	checkedAt time.Time
+	used      uint64
+	gcAt      time.Time
+
+	spillMu  sync.Mutex
+	spillDir string // temporary directory of the run, created on the first spill
+	closed   bool
+}
+
+func NewMemoryLimiter(limit uint64) *MemoryLimiter {
+	if limit == 0 {
+		return nil
+	}
+
+	return &MemoryLimiter{limit: limit}
+}
+
+func (l *MemoryLimiter) usage() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// runtime.ReadMemStats stops the world: don't call it too often.
+	if time.Since(l.checkedAt) >= memoryCheckInterval {
+		var m runtime.MemStats
+		runtime.ReadMemStats(&m)
+		l.used = m.HeapInuse
+		l.checkedAt = time.Now()
+	}
+
+	return l.used
+}
+
+// IsNearLimit reports whether the memory usage is close to the limit.
+func (l *MemoryLimiter) IsNearLimit() bool {
+	if l == nil {
+		return false
+	}
+
+	return l.usage() >= l.limit/100*nearLimitPercent
+}
+
+// Acquire blocks while the memory usage is close to the limit and other packages
+// are being analyzed: it reduces parallelism down to one package at a time.
+// The returned function must be called when the package analysis is done.
+func (l *MemoryLimiter) Acquire(ctx context.Context) (release func()) {
+	if l == nil {
+		return func() {}
+	}
+
+	release = func() {
+		atomic.AddInt32(&l.active, -1)
+	}
+
+	for ctx.Err() == nil {
+		// Check and increment atomically: runners seeing no active packages near the limit
+		// at the same time must not all proceed.
+		active := atomic.LoadInt32(&l.active)
+		if active == 0 || !l.IsNearLimit() {
+			if atomic.CompareAndSwapInt32(&l.active, active, active+1) {
+				return release
+			}
+			continue
+		}
+
+		l.collectGarbage()
+		time.Sleep(memoryCheckInterval)
+	}
+
+	atomic.AddInt32(&l.active, 1)
+	return release
+}
+
+// collectGarbage runs GC at most once per interval: memory released by analyzed
+// packages (see loadingPackage.decUse) isn't reflected by the heap usage until GC.
+func (l *MemoryLimiter) collectGarbage() {
+	l.mu.Lock()
+	if time.Since(l.gcAt) < memoryCheckInterval {
+		l.mu.Unlock()
+		return
+	}
+	l.gcAt = time.Now()
+	l.checkedAt = time.Time{} // recheck the usage after GC
+	l.mu.Unlock()
+
+	runtime.GC()
+}
+
+func spillFileName(key string) string {
+	h := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(h[:])
+}
+
+// Spill gob-encodes data to a temporary file of the run: unlike the persistent
+// cache it's removed by Close, spilled data is needed only during the run.
+func (l *MemoryLimiter) Spill(key string, data interface{}) error {
+	if l == nil {
+		return errors.New("memory isn't limited")
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(data); err != nil {
+		return err
+	}
+
+	l.spillMu.Lock()
+	if l.closed {
+		l.spillMu.Unlock()
+		return errors.New("the run is finished")
+	}
+	if l.spillDir == "" {
+		dir, err := ioutil.TempDir("", "golangci-lint-spill")
+		if err != nil {
+			l.spillMu.Unlock()
+			return err
+		}
+		l.spillDir = dir
+	}
+	dir := l.spillDir
+	l.spillMu.Unlock()
+
+	return ioutil.WriteFile(filepath.Join(dir, spillFileName(key)), buf.Bytes(), 0600)
+}
+
+// Unspill decodes data saved by Spill with the same key.
+func (l *MemoryLimiter) Unspill(key string, data interface{}) error {
+	if l == nil {
+		return errors.New("memory isn't limited")
+	}
+
+	l.spillMu.Lock()
+	dir := l.spillDir
+	l.spillMu.Unlock()
+	if dir == "" {
+		return errors.New("nothing was spilled")
+	}
+
+	f, err := os.Open(filepath.Join(dir, spillFileName(key)))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return gob.NewDecoder(f).Decode(data)
+}
+
+// Close removes spilled data.
+func (l *MemoryLimiter) Close() error {
+	if l == nil {
+		return nil
+	}
+
+	l.spillMu.Lock()
+	defer l.spillMu.Unlock()
+
+	l.closed = true
+	if l.spillDir == "" {
+		return nil
+	}
+
+	err := os.RemoveAll(l.spillDir)
+	l.spillDir = ""
+	return err
+}
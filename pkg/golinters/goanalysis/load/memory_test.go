@@ -0,0 +1,116 @@
+package load
+
+import (
+	"context"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNilMemoryLimiter(t *testing.T) {
+	l := NewMemoryLimiter(0)
+	assert.Nil(t, l)
+
+	assert.False(t, l.IsNearLimit())
+	l.Acquire(context.Background())()
+	assert.Error(t, l.Spill("key", 1))
+	assert.NoError(t, l.Close())
+}
+
+func TestMemoryLimiterAcquireNearLimit(t *testing.T) {
+	l := NewMemoryLimiter(1) // always near the limit
+	require.True(t, l.IsNearLimit())
+
+	release := l.Acquire(context.Background()) // nothing is active: doesn't block
+
+	acquired := make(chan struct{})
+	go func() {
+		l.Acquire(context.Background())()
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("acquired while another package is active near the limit")
+	case <-time.After(3 * memoryCheckInterval):
+	}
+
+	release()
+
+	select {
+	case <-acquired:
+	case <-time.After(time.Minute):
+		t.Fatal("didn't acquire after the release")
+	}
+}
+
+func TestMemoryLimiterAcquireConcurrent(t *testing.T) {
+	l := NewMemoryLimiter(1) // always near the limit
+	require.True(t, l.IsNearLimit())
+
+	const rounds, runners = 5, 8
+	var holders, maxHolders int32
+	for round := 0; round < rounds; round++ {
+		var wg sync.WaitGroup
+		start := make(chan struct{})
+		for i := 0; i < runners; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+
+				release := l.Acquire(context.Background())
+				n := atomic.AddInt32(&holders, 1)
+				for {
+					max := atomic.LoadInt32(&maxHolders)
+					if n <= max || atomic.CompareAndSwapInt32(&maxHolders, max, n) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				atomic.AddInt32(&holders, -1)
+				release()
+			}()
+		}
+
+		close(start) // all runners see no active packages at the same time
+		wg.Wait()
+	}
+
+	assert.Equal(t, int32(1), maxHolders, "only one package may be analyzed at a time near the limit")
+}
+
+func TestMemoryLimiterAcquireCanceled(t *testing.T) {
+	l := NewMemoryLimiter(1)
+	defer l.Acquire(context.Background())()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Returns immediately: the linter is timed out and doesn't start the analysis.
+	l.Acquire(ctx)()
+}
+
+func TestMemoryLimiterSpill(t *testing.T) {
+	l := NewMemoryLimiter(1)
+
+	var got []string
+	assert.Error(t, l.Unspill("key", &got))
+
+	require.NoError(t, l.Spill("key", []string{"a", "b"}))
+	require.NoError(t, l.Unspill("key", &got))
+	assert.Equal(t, []string{"a", "b"}, got)
+	assert.Error(t, l.Unspill("other", &got))
+
+	dir := l.spillDir
+	require.NoError(t, l.Close())
+	_, err := os.Stat(dir)
+	assert.True(t, os.IsNotExist(err))
+
+	assert.Error(t, l.Spill("key", []string{"a"}))
+}
@@ -11,13 +11,13 @@ import (
 )
 
 type MetaLinter struct {
-	linters              []*Linter
-	analyzerToLinterName map[*analysis.Analyzer]string
+	linters          []*Linter
+	analyzerToLinter map[*analysis.Analyzer]*Linter
 }
 
 func NewMetaLinter(linters []*Linter) *MetaLinter {
 	ml := &MetaLinter{linters: linters}
-	ml.analyzerToLinterName = ml.getAnalyzerToLinterNameMapping()
+	ml.analyzerToLinter = ml.getAnalyzerToLinterMapping()
 	return ml
 }
 
@@ -75,17 +75,35 @@ func (ml MetaLinter) reportIssues(lintCtx *linter.Context) []Issue {
 }
 
 func (ml MetaLinter) getLinterNameForDiagnostic(diag *Diagnostic) string {
-	return ml.analyzerToLinterName[diag.Analyzer]
+	return ml.analyzerToLinter[diag.Analyzer].Name()
 }
 
-func (ml MetaLinter) getAnalyzerToLinterNameMapping() map[*analysis.Analyzer]string {
-	analyzerToLinterName := map[*analysis.Analyzer]string{}
+func (ml MetaLinter) getLinterNameForAnalyzer(a *analysis.Analyzer) string {
+	if lnt := ml.analyzerToLinter[a]; lnt != nil {
+		return lnt.Name()
+	}
+	return "" // required analyzer, e.g. inspect
+}
+
+func (ml MetaLinter) getSeverityForDiagnostic(diag *Diagnostic) string {
+	return ml.analyzerToLinter[diag.Analyzer].getSeverityForDiagnostic(diag)
+}
+
+func (ml MetaLinter) getExternalTool(a *analysis.Analyzer) *ExternalTool {
+	if lnt := ml.analyzerToLinter[a]; lnt != nil {
+		return lnt.getExternalTool(a)
+	}
+	return nil
+}
+
+func (ml MetaLinter) getAnalyzerToLinterMapping() map[*analysis.Analyzer]*Linter {
+	analyzerToLinter := map[*analysis.Analyzer]*Linter{}
 	for _, linter := range ml.linters {
 		for _, a := range linter.analyzers {
-			analyzerToLinterName[a] = linter.Name()
+			analyzerToLinter[a] = linter
 		}
 	}
-	return analyzerToLinterName
+	return analyzerToLinter
 }
 
 func (ml MetaLinter) Run(ctx context.Context, lintCtx *linter.Context) ([]result.Issue, error) {
@@ -95,5 +113,5 @@ func (ml MetaLinter) Run(ctx context.Context, lintCtx *linter.Context) ([]result
 		}
 	}
 
-	return runAnalyzers(ml, lintCtx)
+	return runAnalyzers(ctx, ml, lintCtx)
 }
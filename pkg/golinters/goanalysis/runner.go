@@ -11,6 +11,7 @@ package goanalysis
 
 import (
 	"bytes"
+	"context"
 	"encoding/gob"
 	"fmt"
 	"go/ast"
@@ -37,7 +38,9 @@ import (
 	"github.com/golangci/golangci-lint/internal/errorutil"
 	"github.com/golangci/golangci-lint/internal/pkgcache"
 	"github.com/golangci/golangci-lint/pkg/golinters/goanalysis/load"
+	"github.com/golangci/golangci-lint/pkg/lint/linter"
 	"github.com/golangci/golangci-lint/pkg/logutils"
+	"github.com/golangci/golangci-lint/pkg/report"
 	"github.com/golangci/golangci-lint/pkg/timeutils"
 )
 
@@ -82,18 +85,39 @@ type runner struct {
 	passToPkg      map[*analysis.Pass]*packages.Package
 	passToPkgGuard sync.Mutex
 	sw             *timeutils.Stopwatch
+	inFlight       *timeutils.InFlight
+	profile        *report.Profile
+	tracer         *timeutils.Tracer
+	memLimiter     *load.MemoryLimiter
+	progress       *report.Progress
+	analysisSem    chan struct{}
+
+	// hashes of settings of linters owning analyzers: they're a part of facts cache keys
+	settingsHashes map[*analysis.Analyzer]string
+
+	// analyzers run by executables out of process instead of Analyzer.Run
+	externalTools map[*analysis.Analyzer]*ExternalTool
 }
 
-func newRunner(prefix string, logger logutils.Log, pkgCache *pkgcache.Cache, loadGuard *load.Guard,
-	loadMode LoadMode, sw *timeutils.Stopwatch) *runner {
+func newRunner(prefix string, logger logutils.Log, lintCtx *linter.Context, loadMode LoadMode, sw *timeutils.Stopwatch,
+	settingsHashes map[*analysis.Analyzer]string) *runner {
 	return &runner{
-		prefix:    prefix,
-		log:       logger,
-		pkgCache:  pkgCache,
-		loadGuard: loadGuard,
-		loadMode:  loadMode,
-		passToPkg: map[*analysis.Pass]*packages.Package{},
-		sw:        sw,
+		prefix:      prefix,
+		log:         logger,
+		pkgCache:    lintCtx.PkgCache,
+		loadGuard:   lintCtx.LoadGuard,
+		loadMode:    loadMode,
+		passToPkg:   map[*analysis.Pass]*packages.Package{},
+		sw:          sw,
+		inFlight:    lintCtx.InFlight,
+		profile:     lintCtx.Profile,
+		tracer:      lintCtx.Tracer,
+		memLimiter:  lintCtx.MemoryLimiter,
+		progress:    lintCtx.Progress,
+		analysisSem: lintCtx.AnalysisSem,
+
+		settingsHashes: settingsHashes,
+		externalTools:  map[*analysis.Analyzer]*ExternalTool{},
 	}
 }
 
@@ -103,12 +127,12 @@ func newRunner(prefix string, logger logutils.Log, pkgCache *pkgcache.Cache, loa
 // It provides most of the logic for the main functions of both the
 // singlechecker and the multi-analysis commands.
 // It returns the appropriate exit code.
-func (r *runner) run(analyzers []*analysis.Analyzer, initialPackages []*packages.Package) ([]Diagnostic,
+func (r *runner) run(ctx context.Context, analyzers []*analysis.Analyzer, initialPackages []*packages.Package) ([]Diagnostic,
 	[]error, map[*analysis.Pass]*packages.Package) {
 	debugf("Analyzing %d packages on load mode %s", len(initialPackages), r.loadMode)
 	defer r.pkgCache.Trim()
 
-	roots := r.analyze(initialPackages, analyzers)
+	roots := r.analyze(ctx, initialPackages, analyzers)
 	diags, errs := extractDiagnostics(roots)
 	return diags, errs, r.passToPkg
 }
@@ -265,7 +289,7 @@ func (r *runner) prepareAnalysis(pkgs []*packages.Package,
 	return initialPkgs, allActions, roots
 }
 
-func (r *runner) analyze(pkgs []*packages.Package, analyzers []*analysis.Analyzer) []*action {
+func (r *runner) analyze(ctx context.Context, pkgs []*packages.Package, analyzers []*analysis.Analyzer) []*action {
 	initialPkgs, actions, rootActions := r.prepareAnalysis(pkgs, analyzers)
 
 	actionPerPkg := map[*packages.Package][]*action{}
@@ -296,6 +320,7 @@ func (r *runner) analyze(pkgs []*packages.Package, analyzers []*analysis.Analyze
 			log:        r.log,
 			actions:    actionPerPkg[pkg],
 			loadGuard:  r.loadGuard,
+			r:          r,
 			dependents: 1, // self dependent
 		}
 	}
@@ -303,10 +328,12 @@ func (r *runner) analyze(pkgs []*packages.Package, analyzers []*analysis.Analyze
 		dfs(act.pkg)
 	}
 
-	// Limit memory and IO usage.
-	gomaxprocs := runtime.GOMAXPROCS(-1)
-	debugf("Analyzing at most %d packages in parallel", gomaxprocs)
-	loadSem := make(chan struct{}, gomaxprocs)
+	// Limit memory and IO usage: the semaphore is shared by all concurrently running runners.
+	loadSem := r.analysisSem
+	if loadSem == nil {
+		loadSem = make(chan struct{}, runtime.GOMAXPROCS(-1))
+	}
+	debugf("Analyzing at most %d packages in parallel", cap(loadSem))
 
 	var wg sync.WaitGroup
 	debugf("There are %d initial and %d total packages", len(initialPkgs), len(loadingPackages))
@@ -314,7 +341,7 @@ func (r *runner) analyze(pkgs []*packages.Package, analyzers []*analysis.Analyze
 		if lp.isInitial {
 			wg.Add(1)
 			go func(lp *loadingPackage) {
-				lp.analyzeRecursive(r.loadMode, loadSem)
+				lp.analyzeRecursive(ctx, r.loadMode, loadSem)
 				wg.Done()
 			}(lp)
 		}
@@ -405,6 +432,7 @@ type action struct {
 	isroot              bool
 	isInitialPkg        bool
 	needAnalyzeSource   bool
+	factsSpilled        bool // facts are saved to a temporary file and dropped from memory
 }
 
 type objectFactKey struct {
@@ -520,6 +548,9 @@ func (act *action) analyze() {
 		return
 	}
 
+	defer act.r.inFlight.Start("analyzers", fmt.Sprintf("%s (%s)", act, act.r.prefix))()
+	defer act.r.tracer.Start("analyzer", fmt.Sprintf("%s: %s", act.r.prefix, act))()
+
 	defer func(now time.Time) {
 		analyzeDebugf("go/analysis: %s: %s: analyzed package %q in %s", act.r.prefix, act.a.Name, act.pkg.Name, time.Since(now))
 	}(time.Now())
@@ -585,6 +616,8 @@ func (act *action) analyze() {
 		// but govet's cgocall crashes on it. Govet itself contains !pass.Analyzer.RunDespiteErrors condition here
 		// but it exit before it if packages.Load have failed.
 		err = errors.Wrap(&IllTypedError{Pkg: act.pkg}, "analysis skipped")
+	} else if tool := act.r.externalTools[act.a]; tool != nil {
+		act.diagnostics, err = tool.run(act.pkg, act.r.log)
 	} else {
 		startedAt = time.Now()
 		act.result, err = pass.Analyzer.Run(pass)
@@ -609,7 +642,12 @@ func (act *action) analyze() {
 func inheritFacts(act, dep *action) {
 	serialize := false
 
-	for key, fact := range dep.objectFacts {
+	objectFacts, packageFacts := dep.objectFacts, dep.packageFacts
+	if dep.factsSpilled {
+		objectFacts, packageFacts = dep.loadSpilledFacts(act.pkg.Types)
+	}
+
+	for key, fact := range objectFacts {
 		// Filter out facts related to objects
 		// that are irrelevant downstream
 		// (equivalently: not in the compiler export data).
@@ -632,7 +670,7 @@ func inheritFacts(act, dep *action) {
 		act.objectFacts[key] = fact
 	}
 
-	for key, fact := range dep.packageFacts {
+	for key, fact := range packageFacts {
 		// TODO: filter out facts that belong to
 		// packages not mentioned in the export data
 		// to prevent side channels.
@@ -831,14 +869,20 @@ func (act *action) persistFactsToCache() error {
 
 	factsCacheDebugf("Caching %d facts for package %q and analyzer %s", len(facts), act.pkg.Name, act.a.Name)
 
-	key := fmt.Sprintf("%s/facts", analyzer.Name)
-	return act.r.pkgCache.Put(act.pkg, pkgcache.HashModeNeedAllDeps, key, facts)
+	return act.r.pkgCache.Put(act.pkg, pkgcache.HashModeNeedAllDeps, pkgcache.KindFacts, act.factsCacheKey(), facts)
+}
+
+// factsCacheKey depends on settings of the linter: facts of analyzers
+// of other linters aren't invalidated when the settings change.
+func (act *action) factsCacheKey() string {
+	return fmt.Sprintf("%s/facts:%s", act.a.Name, act.r.settingsHashes[act.a])
 }
 
 func (act *action) loadPersistedFacts() bool {
 	var facts []Fact
-	key := fmt.Sprintf("%s/facts", act.a.Name)
-	if err := act.r.pkgCache.Get(act.pkg, pkgcache.HashModeNeedAllDeps, key, &facts); err != nil {
+	err := act.r.pkgCache.Get(act.pkg, pkgcache.HashModeNeedAllDeps, act.factsCacheKey(), &facts)
+	act.r.profile.AddCacheLookup("facts", err == nil)
+	if err != nil {
 		if err != pkgcache.ErrMissing {
 			act.r.log.Warnf("Failed to get persisted facts: %s", err)
 		}
@@ -877,6 +921,99 @@ func (act *action) loadPersistedFacts() bool {
 	return true
 }
 
+type spilledFact struct {
+	PkgPath string
+	Path    string // non-empty only for object facts
+	Fact    analysis.Fact
+}
+
+// spilledFactsKey is unique per runner and package: test variants of a package share its path.
+func (act *action) spilledFactsKey() string {
+	return fmt.Sprintf("%s/%s/%s", act.r.prefix, act.a.Name, act.pkg.ID)
+}
+
+// spillFacts saves own and inherited facts of the action to a temporary file of the run
+// and drops them from memory.
+func (act *action) spillFacts() {
+	if len(act.a.FactTypes) == 0 || act.factsSpilled || act.err != nil {
+		return
+	}
+
+	facts := make([]spilledFact, 0, len(act.packageFacts)+len(act.objectFacts))
+	for key, fact := range act.packageFacts {
+		facts = append(facts, spilledFact{PkgPath: key.pkg.Path(), Fact: fact})
+	}
+	for key, fact := range act.objectFacts {
+		path, err := objectpath.For(key.obj)
+		if err != nil {
+			// The object is not globally addressable: it isn't inherited by dependents anyway.
+			continue
+		}
+		facts = append(facts, spilledFact{PkgPath: key.obj.Pkg().Path(), Path: string(path), Fact: fact})
+	}
+
+	if err := act.r.memLimiter.Spill(act.spilledFactsKey(), facts); err != nil {
+		factsCacheDebugf("Failed to spill %d facts of %s: %s", len(facts), act, err)
+		return
+	}
+
+	factsCacheDebugf("Spilled %d facts of %s", len(facts), act)
+	act.objectFacts = nil
+	act.packageFacts = nil
+	act.factsSpilled = true
+}
+
+// loadSpilledFacts loads spilled facts of the action and resolves their objects by types
+// seen by the importing package view: the package can be reloaded from export data
+// after spilling (see loadingPackage.preferExportData).
+func (act *action) loadSpilledFacts(view *types.Package) (map[objectFactKey]analysis.Fact, map[packageFactKey]analysis.Fact) {
+	objectFacts := map[objectFactKey]analysis.Fact{}
+	packageFacts := map[packageFactKey]analysis.Fact{}
+
+	var facts []spilledFact
+	if err := act.r.memLimiter.Unspill(act.spilledFactsKey(), &facts); err != nil {
+		act.r.log.Warnf("Failed to load spilled facts of %s: %s", act, err)
+		return objectFacts, packageFacts
+	}
+
+	// Facts are about objects of the package and its dependencies: resolve them by the same types.
+	typesPkgs := map[string]*types.Package{}
+	var visit func(pkg *types.Package)
+	visit = func(pkg *types.Package) {
+		if pkg == nil || typesPkgs[pkg.Path()] != nil {
+			return
+		}
+		typesPkgs[pkg.Path()] = pkg
+		for _, imp := range pkg.Imports() {
+			visit(imp)
+		}
+	}
+	visit(view)
+	if act.pkg != nil {
+		visit(act.pkg.Types) // types can be already cleared by decUse
+	}
+
+	for _, f := range facts {
+		pkg := typesPkgs[f.PkgPath]
+		if pkg == nil {
+			continue
+		}
+
+		if f.Path == "" {
+			packageFacts[packageFactKey{pkg, act.factType(f.Fact)}] = f.Fact
+			continue
+		}
+
+		obj, err := objectpath.Object(pkg, objectpath.Path(f.Path))
+		if err != nil {
+			continue // see loadPersistedFacts
+		}
+		objectFacts[objectFactKey{obj, act.factType(f.Fact)}] = f.Fact
+	}
+
+	return objectFacts, packageFacts
+}
+
 type loadingPackage struct {
 	pkg         *packages.Package
 	imports     map[string]*loadingPackage
@@ -884,6 +1021,7 @@ type loadingPackage struct {
 	log         logutils.Log
 	actions     []*action // all actions with this package
 	loadGuard   *load.Guard
+	r           *runner
 	dependents  int32 // number of depending on it packages
 	analyzeOnce sync.Once
 	decUseMutex sync.Mutex
@@ -1012,32 +1150,53 @@ func (lp *loadingPackage) decUse(canClearTypes bool) {
 	lp.actions = nil
 }
 
-func (lp *loadingPackage) analyzeRecursive(loadMode LoadMode, loadSem chan struct{}) {
+func (lp *loadingPackage) analyzeRecursive(ctx context.Context, loadMode LoadMode, loadSem chan struct{}) {
 	lp.analyzeOnce.Do(func() {
 		// Load the direct dependencies, in parallel.
 		var wg sync.WaitGroup
 		wg.Add(len(lp.imports))
 		for _, imp := range lp.imports {
 			go func(imp *loadingPackage) {
-				imp.analyzeRecursive(loadMode, loadSem)
+				imp.analyzeRecursive(ctx, loadMode, loadSem)
 				wg.Done()
 			}(imp)
 		}
 		wg.Wait()
-		lp.analyze(loadMode, loadSem)
+		lp.analyze(ctx, loadMode, loadSem)
 	})
 }
 
-func (lp *loadingPackage) analyze(loadMode LoadMode, loadSem chan struct{}) {
+func (lp *loadingPackage) analyze(ctx context.Context, loadMode LoadMode, loadSem chan struct{}) {
+	// Close to the memory limit analyze packages one by one. Wait for it before
+	// taking the semaphore: waiting packages must not hold its slots.
+	defer lp.r.memLimiter.Acquire(ctx)()
+
 	loadSem <- struct{}{}
 	defer func() {
 		<-loadSem
 	}()
 
+	defer lp.r.inFlight.Start("packages", fmt.Sprintf("%s (%s)", lp, lp.r.prefix))()
+	defer lp.r.tracer.Start("package", fmt.Sprintf("%s: %s", lp.r.prefix, lp))()
+	defer func(pkgPath string, startedAt time.Time) {
+		lp.r.profile.AddPackage(lp.r.prefix, pkgPath, time.Since(startedAt))
+	}(lp.pkg.PkgPath, time.Now())
+
+	if lp.isInitial {
+		defer lp.r.progress.PackageAnalyzed(lp.r.prefix, lp.pkg.PkgPath)
+	}
+
 	// Save memory on unused more fields.
 	defer lp.decUse(loadMode < LoadModeWholeProgram)
 
-	if err := lp.loadWithFacts(loadMode); err != nil {
+	err := ctx.Err() // the linter is timed out: don't start analysis of new packages
+	if err == nil {
+		err = lp.loadWithFacts(loadMode)
+	}
+	if err == nil && lp.isInitial {
+		lp.r.progress.PackageLoaded(lp.r.prefix, lp.pkg.PkgPath)
+	}
+	if err != nil {
 		werr := errors.Wrapf(err, "failed to load package %s", lp.pkg.Name)
 		// Don't need to write error to errCh, it will be extracted and reported on another layer.
 		// Unblock depending actions and propagate error.
@@ -1060,6 +1219,40 @@ func (lp *loadingPackage) analyze(loadMode LoadMode, loadSem chan struct{}) {
 		}(act)
 	}
 	actsWg.Wait()
+
+	if lp.r.memLimiter.IsNearLimit() {
+		// Dependents will load facts from the spill files.
+		for _, act := range lp.actions {
+			act.spillFacts()
+		}
+		lp.preferExportData(loadMode)
+	}
+}
+
+// preferExportData replaces types of the package checked from source by types loaded
+// from export data: they don't keep scopes and objects of function bodies.
+// Importing packages are analyzed after this package, they see only new types.
+// Facts of the package must be spilled: in-memory facts are keyed by old objects.
+func (lp *loadingPackage) preferExportData(loadMode LoadMode) {
+	pkg := lp.pkg
+	if loadMode != LoadModeTypesInfo || pkg.Types == nil || pkg.IllTyped || pkg.ExportFile == "" {
+		return
+	}
+
+	for _, act := range lp.actions {
+		if act.err != nil || (len(act.a.FactTypes) != 0 && !act.factsSpilled) {
+			return
+		}
+	}
+
+	sourceTypes := pkg.Types
+	if err := lp.loadFromExportData(); err != nil {
+		debugf("Failed to reload %s from export data: %s", lp, err)
+		pkg.Types = sourceTypes
+		pkg.IllTyped = false
+		return
+	}
+	debugf("Reloaded %s from export data to save memory", lp)
 }
 
 func (lp *loadingPackage) loadFromSource(loadMode LoadMode) error {
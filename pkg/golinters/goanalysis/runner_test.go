@@ -0,0 +1,81 @@
+package goanalysis
+
+import (
+	"encoding/gob"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/golangci/golangci-lint/pkg/golinters/goanalysis/load"
+	"github.com/golangci/golangci-lint/pkg/logutils"
+)
+
+type testFact struct {
+	Name string
+}
+
+func (*testFact) AFact() {}
+
+func init() {
+	gob.Register(&testFact{})
+}
+
+func checkTestPackage(t *testing.T) *types.Package {
+	const src = "package p\n\nfunc F() {}\n\nvar V int\n"
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, 0)
+	require.NoError(t, err)
+
+	pkg, err := (&types.Config{}).Check("example.com/p", fset, []*ast.File{f}, nil)
+	require.NoError(t, err)
+	return pkg
+}
+
+func TestSpillFactsRoundTrip(t *testing.T) {
+	memLimiter := load.NewMemoryLimiter(1)
+	defer memLimiter.Close()
+
+	r := &runner{prefix: "test", log: logutils.NewStderrLog("test"), memLimiter: memLimiter}
+	a := &analysis.Analyzer{Name: "facts", FactTypes: []analysis.Fact{new(testFact)}}
+	factType := reflect.TypeOf(new(testFact))
+
+	sourceTypes := checkTestPackage(t)
+	act := &action{
+		a:   a,
+		pkg: &packages.Package{ID: "example.com/p", Types: sourceTypes},
+		r:   r,
+		objectFacts: map[objectFactKey]analysis.Fact{
+			{sourceTypes.Scope().Lookup("F"), factType}: &testFact{Name: "func"},
+		},
+		packageFacts: map[packageFactKey]analysis.Fact{
+			{sourceTypes, factType}: &testFact{Name: "pkg"},
+		},
+	}
+
+	act.spillFacts()
+	require.True(t, act.factsSpilled)
+	assert.Nil(t, act.objectFacts)
+	assert.Nil(t, act.packageFacts)
+
+	// The package is reloaded (e.g. from export data) and its types are cleared by decUse:
+	// facts are resolved by the importer's view.
+	act.pkg.Types = nil
+	viewTypes := checkTestPackage(t)
+	objectFacts, packageFacts := act.loadSpilledFacts(viewTypes)
+
+	assert.Equal(t, map[objectFactKey]analysis.Fact{
+		{viewTypes.Scope().Lookup("F"), factType}: &testFact{Name: "func"},
+	}, objectFacts)
+	assert.Equal(t, map[packageFactKey]analysis.Fact{
+		{viewTypes, factType}: &testFact{Name: "pkg"},
+	}, packageFacts)
+}
@@ -33,6 +33,8 @@ func golintProcessPkg(minConfidence float64, files []*ast.File, fset *token.File
 			issues = append(issues, result.Issue{
 				Pos:        ps[idx].Position,
 				Text:       ps[idx].Text,
+				Severity:   result.SeverityInfo,
+				Confidence: golintConfidence(ps[idx].Confidence),
 				FromLinter: golintName,
 			})
 			// TODO: use p.Link and p.Category
@@ -44,6 +46,17 @@ func golintProcessPkg(minConfidence float64, files []*ast.File, fset *token.File
 
 const golintName = "golint"
 
+func golintConfidence(confidence float64) string {
+	switch {
+	case confidence >= 0.9:
+		return result.ConfidenceHigh
+	case confidence >= 0.7:
+		return result.ConfidenceMedium
+	default:
+		return result.ConfidenceLow
+	}
+}
+
 func NewGolint() *goanalysis.Linter {
 	var mu sync.Mutex
 	var resIssues []goanalysis.Issue
@@ -20,6 +20,18 @@ import (
 
 const gosecName = "gosec"
 
+var gosecScoreToSeverity = map[gosec.Score]string{
+	gosec.Low:    result.SeverityInfo,
+	gosec.Medium: result.SeverityWarning,
+	gosec.High:   result.SeverityError,
+}
+
+var gosecScoreToConfidence = map[gosec.Score]string{
+	gosec.Low:    result.ConfidenceLow,
+	gosec.Medium: result.ConfidenceMedium,
+	gosec.High:   result.ConfidenceHigh,
+}
+
 func NewGosec() *goanalysis.Linter {
 	var mu sync.Mutex
 	var resIssues []goanalysis.Issue
@@ -55,7 +67,7 @@ func NewGosec() *goanalysis.Linter {
 
 			res := make([]goanalysis.Issue, 0, len(issues))
 			for _, i := range issues {
-				text := fmt.Sprintf("%s: %s", i.RuleID, i.What) // TODO: use severity and confidence
+				text := fmt.Sprintf("%s: %s", i.RuleID, i.What)
 				var r *result.Range
 				line, err := strconv.Atoi(i.Line)
 				if err != nil {
@@ -80,6 +92,8 @@ func NewGosec() *goanalysis.Linter {
 						Column:   column,
 					},
 					Text:       text,
+					Severity:   gosecScoreToSeverity[i.Severity],
+					Confidence: gosecScoreToConfidence[i.Confidence],
 					LineRange:  r,
 					FromLinter: gosecName,
 				}, pass))
@@ -15,5 +15,5 @@ func NewGosimple() *goanalysis.Linter {
 		"Linter for Go source code that specializes in simplifying a code",
 		analyzers,
 		nil,
-	).WithLoadMode(goanalysis.LoadModeTypesInfo)
+	).WithSeverity(staticcheckSeverity).WithLoadMode(goanalysis.LoadModeTypesInfo)
 }
@@ -38,6 +38,7 @@ import (
 
 	"github.com/golangci/golangci-lint/pkg/config"
 	"github.com/golangci/golangci-lint/pkg/golinters/goanalysis"
+	"github.com/golangci/golangci-lint/pkg/result"
 )
 
 var (
@@ -161,5 +162,29 @@ func NewGovet(cfg *config.GovetSettings) *goanalysis.Linter {
 			"such as Printf calls whose arguments do not align with the format string",
 		analyzersFromConfig(cfg),
 		settings,
-	).WithLoadMode(goanalysis.LoadModeTypesInfo)
+	).WithSeverity(govetSeverity).WithLoadMode(goanalysis.LoadModeTypesInfo)
+}
+
+// govetWarningAnalyzers report suspicious but often harmless code,
+// other analyzers report almost certain bugs.
+var govetWarningAnalyzers = map[string]bool{
+	assign.Analyzer.Name:           true,
+	atomicalign.Analyzer.Name:      true,
+	buildtag.Analyzer.Name:         true,
+	composite.Analyzer.Name:        true,
+	deepequalerrors.Analyzer.Name:  true,
+	findcall.Analyzer.Name:         true,
+	shadow.Analyzer.Name:           true,
+	structtag.Analyzer.Name:        true,
+	testinggoroutine.Analyzer.Name: true,
+	tests.Analyzer.Name:            true,
+	unreachable.Analyzer.Name:      true,
+	unusedresult.Analyzer.Name:     true,
+}
+
+func govetSeverity(a *analysis.Analyzer) string {
+	if govetWarningAnalyzers[a.Name] {
+		return result.SeverityWarning
+	}
+	return result.SeverityError
 }
@@ -2,10 +2,12 @@ package golinters
 
 import (
 	"fmt"
+	"strings"
 
 	"golang.org/x/tools/go/analysis"
 
 	"github.com/golangci/golangci-lint/pkg/logutils"
+	"github.com/golangci/golangci-lint/pkg/result"
 )
 
 var debugf = logutils.Debug("megacheck")
@@ -28,3 +30,18 @@ func setAnalyzersGoVersion(analyzers []*analysis.Analyzer) {
 		}
 	}
 }
+
+// staticcheckSeverity derives the severity of a check from its category:
+// https://staticcheck.io/docs/checks
+func staticcheckSeverity(a *analysis.Analyzer) string {
+	switch {
+	case strings.HasPrefix(a.Name, "SA4"), // code that isn't really doing anything
+		strings.HasPrefix(a.Name, "SA6"), // performance issues
+		strings.HasPrefix(a.Name, "SA9"): // dubious code constructs
+		return result.SeverityWarning
+	case strings.HasPrefix(a.Name, "SA"):
+		return result.SeverityError
+	default: // simple (S) and stylecheck (ST) checks
+		return result.SeverityInfo
+	}
+}
@@ -15,5 +15,5 @@ func NewStaticcheck() *goanalysis.Linter {
 		"Staticcheck is a go vet on steroids, applying a ton of static analysis checks",
 		analyzers,
 		nil,
-	).WithLoadMode(goanalysis.LoadModeTypesInfo)
+	).WithSeverity(staticcheckSeverity).WithLoadMode(goanalysis.LoadModeTypesInfo)
 }
@@ -15,5 +15,5 @@ func NewStylecheck() *goanalysis.Linter {
 		"Stylecheck is a replacement for golint",
 		analyzers,
 		nil,
-	).WithLoadMode(goanalysis.LoadModeTypesInfo)
+	).WithSeverity(staticcheckSeverity).WithLoadMode(goanalysis.LoadModeTypesInfo)
 }
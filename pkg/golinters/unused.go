@@ -31,7 +31,7 @@ func NewUnused() *goanalysis.Linter {
 
 		var issues []goanalysis.Issue
 		for _, ur := range u.Result() {
-			p := u.ProblemObject(lintCtx.Packages[0].Fset, ur)
+			p := u.ProblemObject(lintCtx.OriginalPackages[0].Fset, ur)
 			pkg := typesToPkg[ur.Pkg()]
 			i := &result.Issue{
 				FromLinter: name,
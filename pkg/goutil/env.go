@@ -16,8 +16,11 @@ import (
 type EnvKey string
 
 const (
-	EnvGoCache EnvKey = "GOCACHE"
-	EnvGoRoot  EnvKey = "GOROOT"
+	EnvGoCache    EnvKey = "GOCACHE"
+	EnvGoRoot     EnvKey = "GOROOT"
+	EnvGoMod      EnvKey = "GOMOD"
+	EnvGoModCache EnvKey = "GOMODCACHE"
+	EnvGoVersion  EnvKey = "GOVERSION"
 )
 
 type Env struct {
@@ -37,7 +40,8 @@ func NewEnv(log logutils.Log) *Env {
 func (e *Env) Discover(ctx context.Context) error {
 	startedAt := time.Now()
 	args := []string{"env", "-json"}
-	args = append(args, string(EnvGoCache), string(EnvGoRoot))
+	args = append(args, string(EnvGoCache), string(EnvGoRoot), string(EnvGoMod), string(EnvGoModCache),
+		string(EnvGoVersion))
 	out, err := exec.CommandContext(ctx, "go", args...).Output()
 	if err != nil {
 		return errors.Wrap(err, "failed to run 'go env'")
@@ -1,6 +1,8 @@
 package linter
 
 import (
+	"time"
+
 	"golang.org/x/tools/go/packages"
 )
 
@@ -77,6 +79,18 @@ func (lc *Config) AllNames() []string {
 	return append([]string{lc.Name()}, lc.AlternativeNames...)
 }
 
+// Timeout returns the own timeout of the linter set by its name or by an alternative one,
+// 0 if the linter doesn't have it.
+func (lc *Config) Timeout(timeouts map[string]time.Duration) time.Duration {
+	for _, name := range lc.AllNames() {
+		if timeout, ok := timeouts[name]; ok {
+			return timeout
+		}
+	}
+
+	return 0
+}
+
 func (lc *Config) Name() string {
 	return lc.Linter.Name()
 }
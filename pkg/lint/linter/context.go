@@ -1,8 +1,6 @@
 package linter
 
 import (
-	"go/ast"
-
 	"golang.org/x/tools/go/packages"
 
 	"github.com/golangci/golangci-lint/internal/pkgcache"
@@ -10,6 +8,8 @@ import (
 	"github.com/golangci/golangci-lint/pkg/fsutils"
 	"github.com/golangci/golangci-lint/pkg/golinters/goanalysis/load"
 	"github.com/golangci/golangci-lint/pkg/logutils"
+	"github.com/golangci/golangci-lint/pkg/report"
+	"github.com/golangci/golangci-lint/pkg/timeutils"
 )
 
 type Context struct {
@@ -27,23 +27,70 @@ type Context struct {
 
 	PkgCache  *pkgcache.Cache
 	LoadGuard *load.Guard
+
+	// InFlight tracks running linters, packages and analyzers to show them on timeout
+	InFlight *timeutils.InFlight
+
+	// Profile collects timings and cache stats for --profile-report
+	Profile *report.Profile
+
+	// Tracer records spans for --trace-events
+	Tracer *timeutils.Tracer
+
+	// MemoryLimiter bounds memory usage of go/analysis runners by --max-memory
+	MemoryLimiter *load.MemoryLimiter
+
+	// Progress reports progress of the run by --progress
+	Progress *report.Progress
+
+	// AnalysisSem limits the number of packages analyzed in parallel by all
+	// concurrently running go/analysis runners to --concurrency
+	AnalysisSem chan struct{}
 }
 
 func (c *Context) Settings() *config.LintersSettings {
 	return &c.Cfg.LintersSettings
 }
 
-func (c *Context) ClearTypesInPackages() {
-	for _, p := range c.Packages {
-		clearTypes(p)
+// WithIsolatedPackages returns a copy of the context with a copy of the packages graph.
+// The go/analysis runner saves types and syntax into packages: a linter running
+// on isolated packages doesn't affect other linters, even running concurrently.
+// Types and syntax preloaded by go/packages aren't shared with the copy: the runner
+// loads them again, from export data for dependencies.
+// It must not be called concurrently with linters runs.
+func (c *Context) WithIsolatedPackages() *Context {
+	copies := map[*packages.Package]*packages.Package{}
+	var copyPkg func(pkg *packages.Package) *packages.Package
+	copyPkg = func(pkg *packages.Package) *packages.Package {
+		if cp, ok := copies[pkg]; ok {
+			return cp
+		}
+
+		cp := *pkg
+		cp.Syntax = nil
+		cp.Types = nil
+		cp.TypesInfo = nil
+		copies[pkg] = &cp
+		cp.Imports = make(map[string]*packages.Package, len(pkg.Imports))
+		for path, imp := range pkg.Imports {
+			cp.Imports[path] = copyPkg(imp)
+		}
+		if c.LoadGuard != nil && c.LoadGuard.MutexForPkg(pkg) != nil {
+			c.LoadGuard.AddMutexForPkg(&cp)
+		}
+		return &cp
 	}
-	for _, p := range c.OriginalPackages {
-		clearTypes(p)
+
+	copyPkgs := func(pkgs []*packages.Package) []*packages.Package {
+		ret := make([]*packages.Package, 0, len(pkgs))
+		for _, pkg := range pkgs {
+			ret = append(ret, copyPkg(pkg))
+		}
+		return ret
 	}
-}
 
-func clearTypes(p *packages.Package) {
-	p.Types = nil
-	p.TypesInfo = nil
-	p.Syntax = []*ast.File{}
+	ret := *c
+	ret.Packages = copyPkgs(c.Packages)
+	ret.OriginalPackages = copyPkgs(c.OriginalPackages)
+	return &ret
 }
@@ -132,6 +132,10 @@ func (es EnabledSet) combineGoAnalysisLinters(linters map[string]*linter.Config)
 			// It's ineffective by CPU and memory to run whole-program and incremental analyzers at once.
 			continue
 		}
+		if linter.Timeout(es.cfg.LintersSettings.Timeouts) != 0 {
+			// The linter with its own timeout must run separately to be stopped alone.
+			continue
+		}
 		goanalysisLinters = append(goanalysisLinters, lnt)
 		for _, p := range linter.InPresets {
 			goanalysisPresets[p] = true
@@ -3,6 +3,7 @@ package lintersdb
 import (
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -117,3 +118,26 @@ func TestGetEnabledLintersSet(t *testing.T) {
 		})
 	}
 }
+
+func TestCombineGoAnalysisLintersTimeout(t *testing.T) {
+	m := NewManager(nil, nil)
+	cfg := &config.Config{}
+	// The timeout is set by the alternative name of govet.
+	cfg.LintersSettings.Timeouts = map[string]time.Duration{"vet": time.Minute}
+	es := NewEnabledSet(m, NewValidator(m), nil, cfg)
+
+	linters := map[string]*linter.Config{}
+	for _, name := range []string{"govet", "bodyclose", "ineffassign"} {
+		lcs := m.GetLinterConfigs(name)
+		assert.Len(t, lcs, 1, name)
+		linters[name] = lcs[0]
+	}
+	es.combineGoAnalysisLinters(linters)
+
+	var names []string
+	for name := range linters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	assert.Equal(t, []string{"goanalysis_metalinter", "govet"}, names, "govet with its own timeout runs separately")
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"plugin"
 
+	"github.com/pkg/errors"
 	"golang.org/x/tools/go/analysis"
 
 	"github.com/golangci/golangci-lint/pkg/config"
@@ -347,27 +348,142 @@ func (m Manager) GetAllLinterConfigsForPreset(p string) []*linter.Config {
 }
 
 func (m Manager) loadCustomLinterConfig(name string, settings config.CustomLinterSettings) (*linter.Config, error) {
-	analyzer, err := m.getAnalyzerPlugin(settings.Path)
-	if err != nil {
-		return nil, err
+	var customLinter *goanalysis.Linter
+	switch settings.Type {
+	case "", config.CustomLinterTypeGoPlugin:
+		plug, err := m.getAnalyzerPlugin(settings.Path)
+		if err != nil {
+			return nil, err
+		}
+		if customLinter, err = newPluginLinter(name, settings, plug); err != nil {
+			return nil, err
+		}
+	case config.CustomLinterTypeModule:
+		plug := registeredPlugins[name]
+		if plug == nil {
+			return nil, fmt.Errorf("plugin %s isn't compiled in, build the binary by `golangci-lint custom`", name)
+		}
+		var err error
+		if customLinter, err = newPluginLinter(name, settings, plug); err != nil {
+			return nil, err
+		}
+	case config.CustomLinterTypeExecutable:
+		analyzersSettings, err := getAnalyzersSettings(settings.Settings)
+		if err != nil {
+			return nil, err
+		}
+		tool, err := goanalysis.NewExternalTool(name, settings.Path, analyzersSettings)
+		if err != nil {
+			return nil, err
+		}
+		customLinter = goanalysis.NewExternalLinter(name, settings.Description, tool)
+	default:
+		return nil, fmt.Errorf("unknown custom linter type %q, only %s, %s and %s are supported", settings.Type,
+			config.CustomLinterTypeGoPlugin, config.CustomLinterTypeExecutable, config.CustomLinterTypeModule)
 	}
 	m.log.Infof("Loaded %s: %s", settings.Path, name)
-	customLinter := goanalysis.NewLinter(
-		name,
-		settings.Description,
-		analyzer.GetAnalyzers(),
-		nil).WithLoadMode(goanalysis.LoadModeTypesInfo)
+
 	linterConfig := linter.NewConfig(customLinter)
+	if customLinter.LoadMode() >= goanalysis.LoadModeTypesInfo || settings.Type == config.CustomLinterTypeExecutable {
+		// Executable tools type-check packages by export data of dependencies.
+		linterConfig = linterConfig.WithLoadForGoAnalysis()
+	}
 	linterConfig.EnabledByDefault = true
 	linterConfig.IsSlow = false
 	linterConfig.WithURL(settings.OriginalURL)
 	return linterConfig, nil
 }
 
+func newPluginLinter(name string, settings config.CustomLinterSettings, plug AnalyzerPlugin) (*goanalysis.Linter, error) {
+	var err error
+	var analyzers []*analysis.Analyzer
+	var analyzersSettings map[string]map[string]interface{}
+	if plugWithSettings, ok := plug.(AnalyzerPluginWithSettings); ok {
+		analyzers, err = plugWithSettings.GetAnalyzersWithSettings(settings.Settings)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get analyzers with settings")
+		}
+	} else {
+		analyzers = plug.GetAnalyzers()
+		if analyzersSettings, err = getAnalyzersSettings(settings.Settings); err != nil {
+			return nil, err
+		}
+	}
+
+	loadMode := goanalysis.LoadModeTypesInfo
+	if plugWithLoadMode, ok := plug.(AnalyzerPluginWithLoadMode); ok {
+		if loadMode, err = parseLoadMode(plugWithLoadMode.GetLoadMode()); err != nil {
+			return nil, err
+		}
+	}
+
+	return goanalysis.NewLinter(name, settings.Description, analyzers, analyzersSettings).WithLoadMode(loadMode), nil
+}
+
+// getAnalyzersSettings converts settings of a custom linter to analyzers flags
+// like {"<analyzer>": {"<flag>": <value>}}, see goanalysis.NewLinter.
+func getAnalyzersSettings(settings interface{}) (map[string]map[string]interface{}, error) {
+	if settings == nil {
+		return nil, nil
+	}
+
+	settingsMap, ok := settings.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("settings must be a map of analyzers settings, got %T", settings)
+	}
+
+	ret := map[string]map[string]interface{}{}
+	for analyzerName, analyzerSettings := range settingsMap {
+		analyzerSettingsMap, ok := analyzerSettings.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("settings of analyzer %s must be a map of its flags, got %T", analyzerName, analyzerSettings)
+		}
+		ret[analyzerName] = analyzerSettingsMap
+	}
+	return ret, nil
+}
+
+func parseLoadMode(loadMode string) (goanalysis.LoadMode, error) {
+	for _, lm := range []goanalysis.LoadMode{goanalysis.LoadModeSyntax, goanalysis.LoadModeTypesInfo,
+		goanalysis.LoadModeWholeProgram} {
+		if lm.String() == loadMode {
+			return lm, nil
+		}
+	}
+	return goanalysis.LoadModeNone, fmt.Errorf("unknown load mode %q, only %q, %q and %q are supported", loadMode,
+		goanalysis.LoadModeSyntax, goanalysis.LoadModeTypesInfo, goanalysis.LoadModeWholeProgram)
+}
+
 type AnalyzerPlugin interface {
 	GetAnalyzers() []*analysis.Analyzer
 }
 
+// AnalyzerPluginWithSettings is implemented by plugins getting linters-settings.custom.<name>.settings
+// as is instead of setting analyzers flags by them.
+type AnalyzerPluginWithSettings interface {
+	GetAnalyzersWithSettings(settings interface{}) ([]*analysis.Analyzer, error)
+}
+
+// AnalyzerPluginWithLoadMode is implemented by plugins needing another load mode
+// than "types info": "syntax" or "whole program", see goanalysis.LoadMode.
+type AnalyzerPluginWithLoadMode interface {
+	GetLoadMode() string
+}
+
+// registeredPlugins are plugins compiled into the binary by `golangci-lint custom`.
+var registeredPlugins = map[string]AnalyzerPlugin{}
+
+// RegisterPlugin registers the plugin compiled into the binary: it's enabled by
+// linters-settings.custom.<name> with type module. It must be called from init functions.
+func RegisterPlugin(name string, plug AnalyzerPlugin) {
+	registeredPlugins[name] = plug
+}
+
+// HasRegisteredPlugins returns true if the binary was built by `golangci-lint custom`.
+func HasRegisteredPlugins() bool {
+	return len(registeredPlugins) != 0
+}
+
 func (m Manager) getAnalyzerPlugin(path string) (AnalyzerPlugin, error) {
 	plug, err := plugin.Open(path)
 	if err != nil {
@@ -0,0 +1,61 @@
+package lintersdb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/tools/go/analysis"
+
+	"github.com/golangci/golangci-lint/pkg/config"
+	"github.com/golangci/golangci-lint/pkg/golinters/goanalysis"
+	"github.com/golangci/golangci-lint/pkg/logutils"
+	"github.com/golangci/golangci-lint/pkg/report"
+)
+
+func TestGetAnalyzersSettings(t *testing.T) {
+	settings, err := getAnalyzersSettings(map[string]interface{}{
+		"analyzer": map[string]interface{}{"flag": true},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]map[string]interface{}{"analyzer": {"flag": true}}, settings)
+
+	settings, err = getAnalyzersSettings(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, settings)
+
+	_, err = getAnalyzersSettings(map[string]interface{}{"analyzer": true})
+	assert.Error(t, err)
+}
+
+func TestParseLoadMode(t *testing.T) {
+	loadMode, err := parseLoadMode("syntax")
+	assert.NoError(t, err)
+	assert.Equal(t, goanalysis.LoadModeSyntax, loadMode)
+
+	_, err = parseLoadMode("none")
+	assert.Error(t, err)
+}
+
+type testPlugin struct{}
+
+func (testPlugin) GetAnalyzers() []*analysis.Analyzer {
+	return []*analysis.Analyzer{{Name: "testanalyzer", Doc: "test"}}
+}
+
+func (testPlugin) GetLoadMode() string {
+	return "syntax"
+}
+
+func TestRegisteredPlugin(t *testing.T) {
+	RegisterPlugin("testplugin", testPlugin{})
+	defer delete(registeredPlugins, "testplugin")
+
+	m := NewManager(nil, report.NewLogWrapper(logutils.NewStderrLog(""), &report.Data{}))
+	lc, err := m.loadCustomLinterConfig("testplugin", config.CustomLinterSettings{Type: config.CustomLinterTypeModule})
+	assert.NoError(t, err)
+	assert.Equal(t, "testplugin", lc.Name())
+	assert.Equal(t, goanalysis.LoadModeSyntax, lc.Linter.(*goanalysis.Linter).LoadMode())
+
+	_, err = m.loadCustomLinterConfig("unknown", config.CustomLinterSettings{Type: config.CustomLinterTypeModule})
+	assert.Error(t, err)
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/pkg/errors"
 	"golang.org/x/tools/go/packages"
 
+	"github.com/golangci/golangci-lint/internal/cache"
 	"github.com/golangci/golangci-lint/internal/pkgcache"
 	"github.com/golangci/golangci-lint/pkg/config"
 	"github.com/golangci/golangci-lint/pkg/exitcodes"
@@ -22,6 +23,9 @@ import (
 	"github.com/golangci/golangci-lint/pkg/goutil"
 	"github.com/golangci/golangci-lint/pkg/lint/linter"
 	"github.com/golangci/golangci-lint/pkg/logutils"
+	"github.com/golangci/golangci-lint/pkg/report"
+	"github.com/golangci/golangci-lint/pkg/result/processors"
+	"github.com/golangci/golangci-lint/pkg/timeutils"
 )
 
 type ContextLoader struct {
@@ -34,10 +38,13 @@ type ContextLoader struct {
 	fileCache   *fsutils.FileCache
 	pkgCache    *pkgcache.Cache
 	loadGuard   *load.Guard
+	profile     *report.Profile
+	tracer      *timeutils.Tracer
 }
 
 func NewContextLoader(cfg *config.Config, log logutils.Log, goenv *goutil.Env,
-	lineCache *fsutils.LineCache, fileCache *fsutils.FileCache, pkgCache *pkgcache.Cache, loadGuard *load.Guard) *ContextLoader {
+	lineCache *fsutils.LineCache, fileCache *fsutils.FileCache, pkgCache *pkgcache.Cache, loadGuard *load.Guard,
+	profile *report.Profile, tracer *timeutils.Tracer) *ContextLoader {
 	return &ContextLoader{
 		cfg:         cfg,
 		log:         log,
@@ -48,6 +55,8 @@ func NewContextLoader(cfg *config.Config, log logutils.Log, goenv *goutil.Env,
 		fileCache:   fileCache,
 		pkgCache:    pkgCache,
 		loadGuard:   loadGuard,
+		profile:     profile,
+		tracer:      tracer,
 	}
 }
 
@@ -197,16 +206,43 @@ func (cl *ContextLoader) loadPackages(ctx context.Context, loadMode packages.Loa
 
 	args := cl.buildArgs()
 	cl.debugf("Built loader args are %s", args)
-	pkgs, err := packages.Load(conf, args...)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to load with go/packages")
+
+	// Loading of syntax and types isn't cached: only the result of `go list` is.
+	var pkgs []*packages.Package
+	var cacheKey cache.ActionID
+	useCache := loadMode&^packagesCacheLoadMode == 0
+	if useCache {
+		cacheKey, err = cl.packagesCacheKey(conf, args)
+		if err != nil {
+			cl.debugf("Failed to build packages cache key: %s", err)
+			useCache = false
+		} else {
+			pkgs = cl.loadCachedPackages(cacheKey)
+			cl.profile.AddCacheLookup(string(pkgcache.KindPackages), pkgs != nil)
+		}
 	}
 
-	// Currently, go/packages doesn't guarantee that error will be returned
-	// if context was canceled. See
-	// https://github.com/golang/tools/commit/c5cec6710e927457c3c29d6c156415e8539a5111#r39261855
-	if ctx.Err() != nil {
-		return nil, errors.Wrap(ctx.Err(), "timed out to load packages")
+	if pkgs != nil {
+		cl.log.Infof("Loaded %d packages from cache", len(pkgs))
+	} else {
+		pkgs, err = packages.Load(conf, args...)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load with go/packages")
+		}
+
+		// Currently, go/packages doesn't guarantee that error will be returned
+		// if context was canceled. See
+		// https://github.com/golang/tools/commit/c5cec6710e927457c3c29d6c156415e8539a5111#r39261855
+		if ctx.Err() != nil {
+			return nil, errors.Wrap(ctx.Err(), "timed out to load packages")
+		}
+
+		// Errors can be temporary, e.g. network errors of modules downloading.
+		if useCache && !hasLoadErrors(pkgs) {
+			if err = cl.savePackagesToCache(cacheKey, pkgs); err != nil {
+				cl.log.Infof("Failed to save packages to cache: %s", err)
+			}
+		}
 	}
 
 	if loadMode&packages.NeedSyntax == 0 {
@@ -283,21 +319,167 @@ func (cl *ContextLoader) filterDuplicatePackages(pkgs []*packages.Package) []*pa
 	return retPkgs
 }
 
-func (cl *ContextLoader) Load(ctx context.Context, linters []*linter.Config) (*linter.Context, error) {
+// filterSkippedPackages drops packages all files of which are skipped by skip-dirs
+// or skip-files: all their issues would be thrown away after the analysis anyway.
+// Dropped packages stay in the dependency graph: they are loaded from export data
+// (or from source if analyzers need facts) when analyzed packages import them.
+// Skipped files of analyzed packages aren't excluded: other files can use their
+// declarations, type-checking and package-wide linters (unused, deadcode) need them.
+// Their issues are dropped by the skip processors.
+// Syntax-only linters (gofmt, lll etc.) analyze skipped files of analyzed packages too:
+// excluding the files only for them is out of scope of this filtering, the go/analysis
+// runner saves the parsed files into packages shared with type-checking linters.
+func (cl *ContextLoader) filterSkippedPackages(pkgs []*packages.Package) ([]*packages.Package, error) {
+	skipFiles, skipDirs, err := getSkipProcessors(&cl.cfg.Run, cl.log)
+	if err != nil {
+		return nil, err
+	}
+
+	isSkippedFile := func(path string) bool {
+		relPath, err := fsutils.ShortestRelPath(path, "")
+		if err != nil {
+			cl.debugf("Can't get relative path for %s: %s", path, err)
+			return false
+		}
+
+		if skipFiles.ShouldSkipFile(relPath) {
+			return true
+		}
+
+		relDir := filepath.Dir(relPath)
+		absDir, err := filepath.Abs(relDir)
+		if err != nil {
+			cl.debugf("Can't abs-ify path %q: %s", relDir, err)
+			return false
+		}
+
+		return skipDirs.ShouldSkipDir(relDir, absDir)
+	}
+
+	var retPkgs []*packages.Package
+	for _, pkg := range pkgs {
+		skipped := len(pkg.GoFiles) != 0
+		for _, f := range pkg.GoFiles {
+			if !isSkippedFile(f) {
+				skipped = false
+				break
+			}
+		}
+
+		if skipped {
+			cl.debugf("skip pkg ID=%s because all its files are skipped", pkg.ID)
+			continue
+		}
+
+		retPkgs = append(retPkgs, pkg)
+	}
+
+	if len(retPkgs) != len(pkgs) {
+		cl.log.Infof("Skipped %d packages by skip-dirs and skip-files before analysis", len(pkgs)-len(retPkgs))
+	}
+
+	return retPkgs, nil
+}
+
+// filterUnchangedPackages leaves only packages containing files changed in the diff
+// when only new issues are requested: issues from other packages would be dropped
+// by the diff processor anyway. Whole program linters (unused) still analyze all
+// original packages, facts of dependencies are computed by go/analysis runner.
+// The diff processor reuses the patch read here.
+func (cl *ContextLoader) filterUnchangedPackages(pkgs []*packages.Package,
+	diff *processors.Diff) ([]*packages.Package, error) {
+	if !diff.Enabled() {
+		return pkgs, nil
+	}
+
+	changedFiles, err := diff.ChangedFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	changed := map[string]bool{}
+	for _, f := range changedFiles {
+		absPath, err := filepath.Abs(f)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to abs-ify path %q", f)
+		}
+		changed[absPath] = true
+	}
+
+	var retPkgs []*packages.Package
+	for _, pkg := range pkgs {
+		isChanged := false
+		for _, f := range pkg.GoFiles {
+			if changed[f] {
+				isChanged = true
+				break
+			}
+		}
+
+		if !isChanged {
+			cl.debugf("skip pkg ID=%s because it has no changed files", pkg.ID)
+			continue
+		}
+
+		retPkgs = append(retPkgs, pkg)
+	}
+
+	if len(retPkgs) == 0 {
+		cl.log.Infof("No packages are analyzed: none of %d packages has files changed in the diff (%d changed files)",
+			len(pkgs), len(changedFiles))
+		return nil, nil
+	}
+
+	cl.log.Infof("Analyzing %d of %d packages: %d files are changed in the diff",
+		len(retPkgs), len(pkgs), len(changedFiles))
+	return retPkgs, nil
+}
+
+// Load loads packages for the linters: the diff is used to skip unchanged packages.
+func (cl *ContextLoader) Load(ctx context.Context, linters []*linter.Config, diff *processors.Diff) (*linter.Context, error) {
+	sw := timeutils.NewStopwatch("load", cl.log)
+	cl.profile.AddStagesStopwatch("load", sw)
+
 	loadMode := cl.findLoadMode(linters)
-	pkgs, err := cl.loadPackages(ctx, loadMode)
+	var pkgs []*packages.Package
+	var err error
+	endSpan := cl.tracer.Start("load", "packages loading")
+	sw.TrackStage("packages loading", func() {
+		pkgs, err = cl.loadPackages(ctx, loadMode)
+	})
+	endSpan()
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to load packages")
 	}
 
-	deduplicatedPkgs := cl.filterDuplicatePackages(pkgs)
+	var deduplicatedPkgs []*packages.Package
+	sw.TrackStage("duplicates filtering", func() {
+		deduplicatedPkgs = cl.filterDuplicatePackages(pkgs)
+	})
 
 	if len(deduplicatedPkgs) == 0 {
 		return nil, exitcodes.ErrNoGoFiles
 	}
 
+	var analyzedPkgs []*packages.Package
+	sw.TrackStage("skipped packages filtering", func() {
+		analyzedPkgs, err = cl.filterSkippedPackages(deduplicatedPkgs)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to filter skipped packages")
+	}
+
+	sw.TrackStage("unchanged packages filtering", func() {
+		analyzedPkgs, err = cl.filterUnchangedPackages(analyzedPkgs, diff)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to filter unchanged packages")
+	}
+
 	ret := &linter.Context{
-		Packages: deduplicatedPkgs,
+		// Packages matching skip-dirs and skip-files or without changes
+		// in the diff (--new-from-rev etc.) aren't analyzed.
+		Packages: analyzedPkgs,
 
 		// At least `unused` linters works properly only on original (not deduplicated) packages,
 		// see https://github.com/golangci/golangci-lint/pull/585.
@@ -309,6 +491,8 @@ func (cl *ContextLoader) Load(ctx context.Context, linters []*linter.Config) (*l
 		LineCache: cl.lineCache,
 		PkgCache:  cl.pkgCache,
 		LoadGuard: cl.loadGuard,
+		Profile:   cl.profile,
+		Tracer:    cl.tracer,
 	}
 
 	return ret, nil
@@ -0,0 +1,351 @@
+package lint
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"go/types"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/golangci/golangci-lint/internal/cache"
+	"github.com/golangci/golangci-lint/internal/pkgcache"
+	"github.com/golangci/golangci-lint/pkg/goutil"
+)
+
+// packagesCacheLoadMode is the max load mode the result of which can be cached:
+// it's the result of `go list`, syntax and types aren't cached.
+const packagesCacheLoadMode = packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles |
+	packages.NeedImports | packages.NeedDeps | packages.NeedExportsFile | packages.NeedTypesSizes
+
+// Env variables affecting `go list` in addition to ones from goutil.Env.
+var packagesCacheEnv = []string{
+	"GOOS", "GOARCH", "GOARM", "GO386", "GOAMD64", "CGO_ENABLED", "CC", "CXX",
+	"CGO_CFLAGS", "CGO_CPPFLAGS", "CGO_CXXFLAGS", "CGO_LDFLAGS", "PKG_CONFIG",
+	"GOFLAGS", "GO111MODULE", "GOPATH", "GOWORK", "GOEXPERIMENT",
+}
+
+// cachedPackages is the packages graph loaded by `go list` saved in the cache.
+// File paths are relative to cache roots, see pkgcache.Cache.RelPath.
+type cachedPackages struct {
+	Roots    []string // IDs of initial packages
+	Packages []cachedPackage
+
+	// Files are files out of the module root with their sizes and mtimes: they aren't
+	// a part of the cache key, so they are checked on the cache load.
+	Files map[string]fileStamp
+
+	WordSize, MaxAlign int64 // types.StdSizes, 0 if not loaded
+}
+
+type cachedPackage struct {
+	ID              string
+	Name            string
+	PkgPath         string
+	Errors          []packages.Error
+	GoFiles         []string
+	CompiledGoFiles []string
+	OtherFiles      []string
+	ExportFile      string
+	Imports         map[string]string // import path -> package ID
+}
+
+type fileStamp struct {
+	Size    int64
+	ModTime int64
+}
+
+func statFile(path string) (fileStamp, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}, err
+	}
+	return fileStamp{Size: info.Size(), ModTime: info.ModTime().UnixNano()}, nil
+}
+
+func (cl *ContextLoader) moduleRoot() string {
+	if goMod := cl.goenv.Get(goutil.EnvGoMod); goMod != "" && goMod != os.DevNull {
+		return filepath.Dir(goMod)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	return wd
+}
+
+// packagesCacheKey hashes everything `go list` depends on in the module: the config,
+// env, go.mod, go.sum and the listing of the module directory with sizes and mtimes.
+// Files out of the module root are checked by cachedPackages.Files.
+func (cl *ContextLoader) packagesCacheKey(conf *packages.Config, args []string) (cache.ActionID, error) {
+	key, err := cache.NewHash("packages")
+	if err != nil {
+		return cache.ActionID{}, errors.Wrap(err, "failed to make a hash")
+	}
+
+	fmt.Fprintf(key, "mode %d tests %t\n", conf.Mode, conf.Tests)
+	fmt.Fprintf(key, "build flags %q\n", conf.BuildFlags)
+	fmt.Fprintf(key, "args %q\n", args)
+	for _, k := range []goutil.EnvKey{goutil.EnvGoRoot, goutil.EnvGoModCache, goutil.EnvGoVersion, goutil.EnvGoCache} {
+		fmt.Fprintf(key, "env %s=%s\n", k, cl.goenv.Get(k))
+	}
+	for _, k := range packagesCacheEnv {
+		fmt.Fprintf(key, "env %s=%s\n", k, os.Getenv(k))
+	}
+
+	root := cl.moduleRoot()
+	if root == "" {
+		return cache.ActionID{}, errors.New("failed to find module root")
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return cache.ActionID{}, errors.Wrap(err, "failed to get working directory")
+	}
+	fmt.Fprintf(key, "wd %s\n", cl.pkgCache.RelPath(wd))
+
+	if err := hashModuleListing(key, root); err != nil {
+		return cache.ActionID{}, errors.Wrapf(err, "failed to list module dir %s", root)
+	}
+
+	return key.Sum(), nil
+}
+
+// hashModuleListing writes files of the module affecting `go list` with their sizes and mtimes.
+// Contents of go.mod and go.sum are hashed: they are often rewritten by go commands.
+func hashModuleListing(w io.Writer, root string) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		name := info.Name()
+		if info.IsDir() {
+			// The go command ignores these directories, see `go help packages`.
+			if path != root && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		switch {
+		case name == "go.mod" || name == "go.sum":
+			// Not cache.FileHash: it caches hashes by file names for the process lifetime.
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "file %s %x\n", relPath, sha256.Sum256(data))
+		case name == "modules.txt" || isPackageSourceFile(name):
+			fmt.Fprintf(w, "file %s %d %d\n", relPath, info.Size(), info.ModTime().UnixNano())
+		}
+		return nil
+	})
+}
+
+func isPackageSourceFile(name string) bool {
+	switch filepath.Ext(name) {
+	case ".go", ".c", ".h", ".cc", ".cpp", ".cxx", ".hh", ".hpp", ".hxx", ".m", ".s", ".S", ".sx", ".f", ".F", ".for", ".f90", ".syso", ".swig", ".swigcxx":
+		return true
+	}
+	return false
+}
+
+func hasLoadErrors(roots []*packages.Package) bool {
+	hasErrors := false
+	packages.Visit(roots, nil, func(pkg *packages.Package) {
+		if len(pkg.Errors) != 0 {
+			hasErrors = true
+		}
+	})
+	return hasErrors
+}
+
+// loadCachedPackages returns packages loaded from the cache or nil if they aren't there or outdated.
+func (cl *ContextLoader) loadCachedPackages(key cache.ActionID) []*packages.Package {
+	var cached cachedPackages
+	if err := cl.pkgCache.GetData(pkgcache.KindPackages, key, &cached); err != nil {
+		if err != pkgcache.ErrMissing {
+			cl.debugf("Failed to load packages from cache: %s", err)
+		}
+		return nil
+	}
+
+	for relPath, stamp := range cached.Files {
+		path := cl.pkgCache.AbsPath(relPath)
+		if curStamp, err := statFile(path); err != nil || curStamp != stamp {
+			cl.debugf("Cached packages are outdated: file %s changed", path)
+			return nil
+		}
+	}
+
+	var sizes types.Sizes
+	if cached.WordSize != 0 {
+		sizes = &types.StdSizes{WordSize: cached.WordSize, MaxAlign: cached.MaxAlign}
+	}
+
+	absPaths := func(relPaths []string) []string {
+		if relPaths == nil {
+			return nil
+		}
+		ret := make([]string, 0, len(relPaths))
+		for _, p := range relPaths {
+			ret = append(ret, cl.pkgCache.AbsPath(p))
+		}
+		return ret
+	}
+
+	pkgByID := make(map[string]*packages.Package, len(cached.Packages))
+	for i := range cached.Packages {
+		cp := &cached.Packages[i]
+		pkg := &packages.Package{
+			ID:              cp.ID,
+			Name:            cp.Name,
+			PkgPath:         cp.PkgPath,
+			Errors:          cp.Errors,
+			GoFiles:         absPaths(cp.GoFiles),
+			CompiledGoFiles: absPaths(cp.CompiledGoFiles),
+			OtherFiles:      absPaths(cp.OtherFiles),
+			TypesSizes:      sizes,
+		}
+		if cp.ExportFile != "" {
+			pkg.ExportFile = cl.pkgCache.AbsPath(cp.ExportFile)
+			if _, err := os.Stat(pkg.ExportFile); err != nil {
+				cl.debugf("Cached packages are outdated: export file %s of %s is missing", pkg.ExportFile, pkg.ID)
+				return nil
+			}
+		}
+		pkgByID[pkg.ID] = pkg
+	}
+
+	for i := range cached.Packages {
+		cp := &cached.Packages[i]
+		pkg := pkgByID[cp.ID]
+		pkg.Imports = make(map[string]*packages.Package, len(cp.Imports))
+		for path, id := range cp.Imports {
+			imp := pkgByID[id]
+			if imp == nil {
+				cl.debugf("Cached packages are broken: no package %s imported by %s", id, cp.ID)
+				return nil
+			}
+			pkg.Imports[path] = imp
+		}
+	}
+
+	roots := make([]*packages.Package, 0, len(cached.Roots))
+	for _, id := range cached.Roots {
+		pkg := pkgByID[id]
+		if pkg == nil {
+			cl.debugf("Cached packages are broken: no root package %s", id)
+			return nil
+		}
+		roots = append(roots, pkg)
+	}
+	return roots
+}
+
+// savePackagesToCache saves the packages graph loaded by `go list` for loadCachedPackages.
+func (cl *ContextLoader) savePackagesToCache(key cache.ActionID, roots []*packages.Package) error {
+	root := cl.moduleRoot()
+	cached := cachedPackages{Files: map[string]fileStamp{}}
+
+	// Files in GOROOT and in the modules cache don't change: they are a part of the key by env.
+	var immutableDirs []string
+	for _, k := range []goutil.EnvKey{goutil.EnvGoRoot, goutil.EnvGoModCache} {
+		if dir := cl.goenv.Get(k); dir != "" {
+			immutableDirs = append(immutableDirs, dir)
+		}
+	}
+	isInDir := func(path, dir string) bool {
+		rel, err := filepath.Rel(dir, path)
+		return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+	}
+
+	var stampErr error
+	addFiles := func(paths []string) []string {
+		if paths == nil {
+			return nil
+		}
+		ret := make([]string, 0, len(paths))
+		for _, path := range paths {
+			relPath := cl.pkgCache.RelPath(path)
+			ret = append(ret, relPath)
+
+			if isInDir(path, root) {
+				continue
+			}
+			immutable := false
+			for _, dir := range immutableDirs {
+				if isInDir(path, dir) {
+					immutable = true
+					break
+				}
+			}
+			if immutable {
+				continue
+			}
+
+			stamp, err := statFile(path)
+			if err != nil {
+				stampErr = err
+				continue
+			}
+			cached.Files[relPath] = stamp
+
+			// A new file in the package directory changes its mtime.
+			dir := filepath.Dir(path)
+			if dirStamp, err := statFile(dir); err == nil {
+				cached.Files[cl.pkgCache.RelPath(dir)] = dirStamp
+			}
+		}
+		return ret
+	}
+
+	packages.Visit(roots, nil, func(pkg *packages.Package) {
+		cp := cachedPackage{
+			ID:              pkg.ID,
+			Name:            pkg.Name,
+			PkgPath:         pkg.PkgPath,
+			Errors:          pkg.Errors,
+			GoFiles:         addFiles(pkg.GoFiles),
+			CompiledGoFiles: addFiles(pkg.CompiledGoFiles),
+			OtherFiles:      addFiles(pkg.OtherFiles),
+			Imports:         make(map[string]string, len(pkg.Imports)),
+		}
+		if pkg.ExportFile != "" {
+			cp.ExportFile = cl.pkgCache.RelPath(pkg.ExportFile)
+		}
+		for path, imp := range pkg.Imports {
+			cp.Imports[path] = imp.ID
+		}
+		cached.Packages = append(cached.Packages, cp)
+
+		if sizes, ok := pkg.TypesSizes.(*types.StdSizes); ok && sizes != nil {
+			cached.WordSize, cached.MaxAlign = sizes.WordSize, sizes.MaxAlign
+		}
+	})
+	if stampErr != nil {
+		return errors.Wrap(stampErr, "failed to stat package file")
+	}
+
+	for _, pkg := range roots {
+		cached.Roots = append(cached.Roots, pkg.ID)
+	}
+	sort.Slice(cached.Packages, func(i, j int) bool {
+		return cached.Packages[i].ID < cached.Packages[j].ID
+	})
+
+	return cl.pkgCache.PutData(pkgcache.KindPackages, key, cached)
+}
@@ -0,0 +1,239 @@
+package lint
+
+import (
+	"bytes"
+	"fmt"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/golangci/golangci-lint/internal/cache"
+	"github.com/golangci/golangci-lint/internal/pkgcache"
+	"github.com/golangci/golangci-lint/pkg/goutil"
+	"github.com/golangci/golangci-lint/pkg/logutils"
+	"github.com/golangci/golangci-lint/pkg/timeutils"
+)
+
+func TestMain(m *testing.M) {
+	// The packages cache is stored in the default cache: don't touch the user's one.
+	dir, err := ioutil.TempDir("", "golangci-lint-cache-")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("GOLANGCI_LINT_CACHE", dir)
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+}
+
+func hashTestModule(t *testing.T, root string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, hashModuleListing(&buf, root))
+	return buf.String()
+}
+
+func TestHashModuleListing(t *testing.T) {
+	root, err := ioutil.TempDir("", "golangci-lint-module-")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	writeTestFile(t, filepath.Join(root, "go.mod"), "module m\n")
+	writeTestFile(t, filepath.Join(root, "a", "a.go"), "package a\n")
+	writeTestFile(t, filepath.Join(root, "README.md"), "readme\n")
+	writeTestFile(t, filepath.Join(root, "testdata", "t.go"), "package t\n")
+	writeTestFile(t, filepath.Join(root, ".git", "g.go"), "package g\n")
+	hash := hashTestModule(t, root)
+
+	// Files not affecting `go list` don't change the hash.
+	writeTestFile(t, filepath.Join(root, "README.md"), "changed readme\n")
+	writeTestFile(t, filepath.Join(root, "testdata", "t.go"), "package changed\n")
+	writeTestFile(t, filepath.Join(root, ".git", "g2.go"), "package g\n")
+	assert.Equal(t, hash, hashTestModule(t, root))
+
+	// go.mod is hashed by its content: rewriting it with the same content doesn't matter.
+	writeTestFile(t, filepath.Join(root, "go.mod"), "module m\n")
+	future := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(root, "go.mod"), future, future))
+	assert.Equal(t, hash, hashTestModule(t, root))
+
+	writeTestFile(t, filepath.Join(root, "go.mod"), "module m2\n")
+	changedGoMod := hashTestModule(t, root)
+	assert.NotEqual(t, hash, changedGoMod)
+
+	writeTestFile(t, filepath.Join(root, "a", "a.go"), "package a // edited\n")
+	editedFile := hashTestModule(t, root)
+	assert.NotEqual(t, changedGoMod, editedFile)
+
+	writeTestFile(t, filepath.Join(root, "a", "b.go"), "package a\n")
+	assert.NotEqual(t, editedFile, hashTestModule(t, root))
+}
+
+type packagesCacheTest struct {
+	t       *testing.T
+	cl      *ContextLoader
+	module  string // the module root
+	outside string // a directory of a package out of the module
+	key     cache.ActionID
+}
+
+func newPackagesCacheTest(t *testing.T) *packagesCacheTest {
+	module, err := ioutil.TempDir("", "golangci-lint-module-")
+	require.NoError(t, err)
+	outside, err := ioutil.TempDir("", "golangci-lint-outside-")
+	require.NoError(t, err)
+
+	writeTestFile(t, filepath.Join(module, "go.mod"), "module m\n")
+	writeTestFile(t, filepath.Join(module, "a", "a.go"), "package a\n")
+	writeTestFile(t, filepath.Join(outside, "dep.go"), "package dep\n")
+	writeTestFile(t, filepath.Join(outside, "dep.a"), "export data\n")
+
+	log := logutils.NewStderrLog("test")
+	pkgCache, err := pkgcache.NewCache(timeutils.NewStopwatch("test", log), nil, log)
+	require.NoError(t, err)
+	pkgCache.SetRoots(map[string]string{"MODROOT": module})
+
+	goenv := goutil.NewEnv(log)
+	require.NoError(t, os.Setenv(string(goutil.EnvGoMod), filepath.Join(module, "go.mod")))
+
+	key, err := cache.NewHash("packages cache test")
+	require.NoError(t, err)
+	fmt.Fprintf(key, "module %s\n", module) // a unique key per test
+
+	return &packagesCacheTest{
+		t: t,
+		cl: &ContextLoader{
+			log:      log,
+			debugf:   logutils.Debug("loader"),
+			goenv:    goenv,
+			pkgCache: pkgCache,
+		},
+		module:  module,
+		outside: outside,
+		key:     key.Sum(),
+	}
+}
+
+func (pt *packagesCacheTest) cleanup() {
+	os.Unsetenv(string(goutil.EnvGoMod))
+	os.RemoveAll(pt.module)
+	os.RemoveAll(pt.outside)
+}
+
+// save saves the package "m/a" of the module importing the package "dep" out of the module.
+func (pt *packagesCacheTest) save() {
+	dep := &packages.Package{
+		ID:         "dep",
+		Name:       "dep",
+		PkgPath:    "dep",
+		GoFiles:    []string{filepath.Join(pt.outside, "dep.go")},
+		ExportFile: filepath.Join(pt.outside, "dep.a"),
+	}
+	a := &packages.Package{
+		ID:         "m/a",
+		Name:       "a",
+		PkgPath:    "m/a",
+		GoFiles:    []string{filepath.Join(pt.module, "a", "a.go")},
+		Imports:    map[string]*packages.Package{"dep": dep},
+		TypesSizes: &types.StdSizes{WordSize: 8, MaxAlign: 8},
+	}
+	require.NoError(pt.t, pt.cl.savePackagesToCache(pt.key, []*packages.Package{a}))
+}
+
+func TestPackagesCacheHit(t *testing.T) {
+	pt := newPackagesCacheTest(t)
+	defer pt.cleanup()
+	pt.save()
+
+	roots := pt.cl.loadCachedPackages(pt.key)
+	require.Len(t, roots, 1)
+	a := roots[0]
+	assert.Equal(t, "m/a", a.ID)
+	assert.Equal(t, []string{filepath.Join(pt.module, "a", "a.go")}, a.GoFiles)
+	assert.Equal(t, &types.StdSizes{WordSize: 8, MaxAlign: 8}, a.TypesSizes)
+
+	require.Contains(t, a.Imports, "dep")
+	dep := a.Imports["dep"]
+	assert.Equal(t, "dep", dep.ID)
+	assert.Equal(t, []string{filepath.Join(pt.outside, "dep.go")}, dep.GoFiles)
+	assert.Equal(t, filepath.Join(pt.outside, "dep.a"), dep.ExportFile)
+
+	// `cache export` exports the packages graph too.
+	ids, err := pt.cl.pkgCache.EntryIDs(nil, "")
+	require.NoError(t, err)
+	assert.Contains(t, ids, pt.key)
+
+	// Files in the module are a part of the key: their changes don't invalidate the entry.
+	writeTestFile(t, filepath.Join(pt.module, "a", "a.go"), "package a // edited\n")
+	assert.NotNil(t, pt.cl.loadCachedPackages(pt.key))
+}
+
+func TestPackagesCacheOutOfModuleFileEdited(t *testing.T) {
+	pt := newPackagesCacheTest(t)
+	defer pt.cleanup()
+	pt.save()
+
+	writeTestFile(t, filepath.Join(pt.outside, "dep.go"), "package dep // edited\n")
+	assert.Nil(t, pt.cl.loadCachedPackages(pt.key))
+}
+
+func TestPackagesCacheOutOfModuleFileAdded(t *testing.T) {
+	pt := newPackagesCacheTest(t)
+	defer pt.cleanup()
+	pt.save()
+
+	// A new file changes the mtime of the package directory.
+	writeTestFile(t, filepath.Join(pt.outside, "new.go"), "package dep\n")
+	future := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(pt.outside, future, future))
+	assert.Nil(t, pt.cl.loadCachedPackages(pt.key))
+}
+
+func TestPackagesCacheMissingExportFile(t *testing.T) {
+	pt := newPackagesCacheTest(t)
+	defer pt.cleanup()
+	pt.save()
+
+	require.NoError(t, os.Remove(filepath.Join(pt.outside, "dep.a")))
+	assert.Nil(t, pt.cl.loadCachedPackages(pt.key))
+}
+
+func TestPackagesCacheBroken(t *testing.T) {
+	pt := newPackagesCacheTest(t)
+	defer pt.cleanup()
+
+	// Missing entry.
+	assert.Nil(t, pt.cl.loadCachedPackages(pt.key))
+
+	// Data of another type.
+	require.NoError(t, pt.cl.pkgCache.PutData(pkgcache.KindPackages, pt.key, "not packages"))
+	assert.Nil(t, pt.cl.loadCachedPackages(pt.key))
+
+	// Import of a package missing in the graph.
+	require.NoError(t, pt.cl.pkgCache.PutData(pkgcache.KindPackages, pt.key, cachedPackages{
+		Roots:    []string{"m/a"},
+		Packages: []cachedPackage{{ID: "m/a", Imports: map[string]string{"dep": "dep"}}},
+	}))
+	assert.Nil(t, pt.cl.loadCachedPackages(pt.key))
+
+	// Missing root package.
+	require.NoError(t, pt.cl.pkgCache.PutData(pkgcache.KindPackages, pt.key, cachedPackages{
+		Roots:    []string{"m/b"},
+		Packages: []cachedPackage{{ID: "m/a"}},
+	}))
+	assert.Nil(t, pt.cl.loadCachedPackages(pt.key))
+}
@@ -6,6 +6,8 @@ import (
 	"os"
 	"runtime/debug"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -17,6 +19,7 @@ import (
 	"github.com/golangci/golangci-lint/pkg/lint/lintersdb"
 	"github.com/golangci/golangci-lint/pkg/logutils"
 	"github.com/golangci/golangci-lint/pkg/packages"
+	"github.com/golangci/golangci-lint/pkg/report"
 	"github.com/golangci/golangci-lint/pkg/result"
 	"github.com/golangci/golangci-lint/pkg/result/processors"
 	"github.com/golangci/golangci-lint/pkg/timeutils"
@@ -27,20 +30,20 @@ import (
 type Runner struct {
 	Processors []processors.Processor
 	Log        logutils.Log
+
+	reportData      *report.Data
+	failThreshold   *processors.FailThreshold
+	explainFiltered bool
+	concurrency     int
+
+	allowLinterFailures bool
+	linterTimeouts      map[string]time.Duration
 }
 
 func NewRunner(cfg *config.Config, log logutils.Log, goenv *goutil.Env, es *lintersdb.EnabledSet,
-	lineCache *fsutils.LineCache, dbManager *lintersdb.Manager, pkgs []*gopackages.Package) (*Runner, error) {
-	skipFilesProcessor, err := processors.NewSkipFiles(cfg.Run.SkipFiles)
-	if err != nil {
-		return nil, err
-	}
-
-	skipDirs := cfg.Run.SkipDirs
-	if cfg.Run.UseDefaultSkipDirs {
-		skipDirs = append(skipDirs, packages.StdExcludeDirRegexps...)
-	}
-	skipDirsProcessor, err := processors.NewSkipDirs(skipDirs, log.Child("skip dirs"), cfg.Run.Args)
+	lineCache *fsutils.LineCache, dbManager *lintersdb.Manager, pkgs []*gopackages.Package,
+	diff *processors.Diff, reportData *report.Data) (*Runner, error) {
+	skipFilesProcessor, skipDirsProcessor, err := getSkipProcessors(&cfg.Run, log)
 	if err != nil {
 		return nil, err
 	}
@@ -50,6 +53,8 @@ func NewRunner(cfg *config.Config, log logutils.Log, goenv *goutil.Env, es *lint
 		return nil, errors.Wrap(err, "failed to get enabled linters")
 	}
 
+	failThreshold := processors.NewFailThreshold(cfg)
+
 	return &Runner{
 		Processors: []processors.Processor{
 			processors.NewCgo(goenv),
@@ -71,19 +76,44 @@ func NewRunner(cfg *config.Config, log logutils.Log, goenv *goutil.Env, es *lint
 			getExcludeRulesProcessor(&cfg.Issues, log, lineCache),
 			processors.NewNolint(log.Child("nolint"), dbManager, enabledLinters),
 
+			// Must be before uniq by line: otherwise duplicates are dropped without merging.
+			processors.NewMergeDuplicates(cfg),
 			processors.NewUniqByLine(cfg),
-			processors.NewDiff(cfg.Issues.Diff, cfg.Issues.DiffFromRevision, cfg.Issues.DiffPatchFilePath),
-			processors.NewMaxPerFileFromLinter(cfg),
+			diff,
+
+			// Must be before limits: hidden issues must not use up them.
+			getSeverityRulesProcessor(&cfg.Severity, log, lineCache),
+			processors.NewMinSeverity(cfg.Severity.MinSeverity), // must be after severity rules
+			failThreshold, // must be after min severity and before limits
+
+			processors.NewMaxPerFileFromLinter(cfg, log.Child("max_per_file_from_linter")),
 			processors.NewMaxSameIssues(cfg.Issues.MaxSameIssues, log.Child("max_same_issues"), cfg),
 			processors.NewMaxFromLinter(cfg.Issues.MaxIssuesPerLinter, log.Child("max_from_linter"), cfg),
 			processors.NewSourceCode(lineCache, log.Child("source_code")),
 			processors.NewPathShortener(),
-			getSeverityRulesProcessor(&cfg.Severity, log, lineCache),
 		},
-		Log: log,
+		Log:             log,
+		reportData:      reportData,
+		failThreshold:   failThreshold,
+		explainFiltered: cfg.Output.ExplainFiltered,
+		concurrency:     cfg.Run.Concurrency,
+
+		allowLinterFailures: cfg.Run.AllowLinterFailures || isGolangciComRun(log),
+		linterTimeouts:      cfg.LintersSettings.Timeouts,
 	}, nil
 }
 
+// isGolangciComRun checks the GOLANGCI_COM_RUN env var: golangci.com set it to not
+// stop on linter failures before --allow-linter-failures was added, it's a deprecated alias now.
+func isGolangciComRun(log logutils.Log) bool {
+	if os.Getenv("GOLANGCI_COM_RUN") == "" {
+		return false
+	}
+
+	log.Warnf("GOLANGCI_COM_RUN env var is deprecated, use --allow-linter-failures instead")
+	return true
+}
+
 func (r *Runner) runLinterSafe(ctx context.Context, lintCtx *linter.Context,
 	lc *linter.Config) (ret []result.Issue, err error) {
 	defer func() {
@@ -99,16 +129,6 @@ func (r *Runner) runLinterSafe(ctx context.Context, lintCtx *linter.Context,
 	}()
 
 	issues, err := lc.Linter.Run(ctx, lintCtx)
-
-	if lc.DoesChangeTypes {
-		// Packages in lintCtx might be dirty due to the last analysis,
-		// which affects to the next analysis.
-		// To avoid this issue, we clear type information from the packages.
-		// See https://github.com/golangci/golangci-lint/pull/944.
-		// Currently DoesChangeTypes is true only for `unused`.
-		lintCtx.ClearTypesInPackages()
-	}
-
 	if err != nil {
 		return nil, err
 	}
@@ -127,8 +147,9 @@ type processorStat struct {
 	outCount int
 }
 
-func (r Runner) processLintResults(inIssues []result.Issue) []result.Issue {
+func (r Runner) processLintResults(inIssues []result.Issue, lintCtx *linter.Context) []result.Issue {
 	sw := timeutils.NewStopwatch("processing", r.Log)
+	lintCtx.Profile.AddStagesStopwatch("processing", sw)
 
 	var issuesBefore, issuesAfter int
 	statPerProcessor := map[string]processorStat{}
@@ -136,7 +157,7 @@ func (r Runner) processLintResults(inIssues []result.Issue) []result.Issue {
 	var outIssues []result.Issue
 	if len(inIssues) != 0 {
 		issuesBefore += len(inIssues)
-		outIssues = r.processIssues(inIssues, sw, statPerProcessor)
+		outIssues = r.processIssues(inIssues, sw, statPerProcessor, lintCtx.Tracer)
 		issuesAfter += len(outIssues)
 	}
 
@@ -144,9 +165,11 @@ func (r Runner) processLintResults(inIssues []result.Issue) []result.Issue {
 
 	for _, p := range r.Processors {
 		p := p
+		endSpan := lintCtx.Tracer.Start("processor", p.Name()+" finish")
 		sw.TrackStage(p.Name(), func() {
 			p.Finish()
 		})
+		endSpan()
 	}
 
 	if issuesBefore != issuesAfter {
@@ -173,36 +196,213 @@ func (r Runner) printPerProcessorStat(stat map[string]processorStat) {
 func (r Runner) Run(ctx context.Context, linters []*linter.Config, lintCtx *linter.Context) ([]result.Issue, error) {
 	sw := timeutils.NewStopwatch("linters", r.Log)
 	defer sw.Print()
+	lintCtx.InFlight.AddStopwatch("linters", sw)
+	lintCtx.Profile.AddLintersStopwatch(sw)
+
+	parallelism := r.concurrency
+	if parallelism > len(linters) {
+		parallelism = len(linters)
+	}
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	lintCtxs, sharedCtxs := r.makeLintersContexts(linters, lintCtx, parallelism)
+
+	linterIssues := make([][]result.Issue, len(linters))
+	linterErrs := make([]error, len(linters))
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for i, lc := range linters {
+		i, lc := i, lc
+		sem <- struct{}{}
+		if err := ctx.Err(); err != nil {
+			// The run is timed out: don't start remaining linters.
+			linterErrs[i] = err
+			<-sem
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			linterCtx := lintCtxs[i]
+			if linterCtx == nil {
+				// The pool can be smaller than parallelism: wait for a free shared context.
+				select {
+				case linterCtx = <-sharedCtxs:
+				case <-ctx.Done():
+					linterErrs[i] = ctx.Err()
+					return
+				}
+				defer func() {
+					sharedCtxs <- linterCtx
+				}()
+			}
+
+			// The stage is tracked inside of the goroutine to not count waiting for the semaphore.
+			sw.TrackStage(lc.Name(), func() {
+				linterIssues[i], linterErrs[i] = r.runLinterWithTimeout(ctx, linterCtx, lc)
+			})
+		}()
+	}
+	wg.Wait()
 
 	var issues []result.Issue
 	var runErr error
-	for _, lc := range linters {
-		lc := lc
-		sw.TrackStage(lc.Name(), func() {
-			linterIssues, err := r.runLinterSafe(ctx, lintCtx, lc)
-			if err != nil {
-				r.Log.Warnf("Can't run linter %s: %s", lc.Linter.Name(), err)
-				if os.Getenv("GOLANGCI_COM_RUN") == "" {
-					// Don't stop all linters on one linter failure for golangci.com.
-					runErr = err
-				}
-				return
+	for i, lc := range linters {
+		err := linterErrs[i]
+		if err == nil {
+			issues = append(issues, linterIssues[i]...)
+			continue
+		}
+
+		var timeoutErr *linterTimeoutError
+		switch {
+		case errors.As(err, &timeoutErr):
+			r.reportData.AddLinterFailure(lc.Name(), err)
+			r.Log.Warnf("Linter %s %s, its issues aren't reported", lc.Name(), err)
+		case ctx.Err() != nil && isContextError(err):
+			// Issues of finished linters are still reported, the run is marked as partial.
+			r.reportData.Partial = true
+			r.reportData.AddUnfinishedLinter(lc.Name(), errors.New("didn't finish before the run timeout"))
+			r.Log.Infof("Linter %s didn't finish before the timeout", lc.Name())
+		default:
+			r.reportData.AddLinterFailure(lc.Name(), err)
+			r.Log.Warnf("Can't run linter %s: %s", lc.Linter.Name(), err)
+			if !r.allowLinterFailures {
+				runErr = err
 			}
-			issues = append(issues, linterIssues...)
-		})
+		}
+	}
+
+	issues = r.processLintResults(issues, lintCtx)
+	if r.failThreshold.Reached() {
+		r.reportData.FailThresholdReached = true
 	}
+	return issues, runErr
+}
+
+// linterStopGracePeriod is how long a timed out linter is waited for after the cancellation:
+// the go/analysis runner finishes packages being analyzed and doesn't start new ones.
+const linterStopGracePeriod = 10 * time.Second
 
-	return r.processLintResults(issues), runErr
+// linterTimeoutError is returned when the linter's own timeout fires.
+type linterTimeoutError struct {
+	timeout time.Duration
 }
 
-func (r *Runner) processIssues(issues []result.Issue, sw *timeutils.Stopwatch, statPerProcessor map[string]processorStat) []result.Issue {
+func (e *linterTimeoutError) Error() string {
+	return fmt.Sprintf("timed out after %s", e.timeout)
+}
+
+func isContextError(err error) bool {
+	cause := errors.Cause(err)
+	return cause == context.DeadlineExceeded || cause == context.Canceled
+}
+
+// runLinterWithTimeout cancels the linter on the global or the linter's own timeout
+// and waits for it to stop: a timed out linter must not use CPU and memory
+// while next linters run. A linter not stopped in the grace period is abandoned.
+func (r Runner) runLinterWithTimeout(ctx context.Context, lintCtx *linter.Context,
+	lc *linter.Config) ([]result.Issue, error) {
+	linterCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	timeout := lc.Timeout(r.linterTimeouts)
+	var timeoutCh <-chan time.Time
+	if timeout != 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timeoutCh = timer.C
+	}
+
+	type runResult struct {
+		issues []result.Issue
+		err    error
+	}
+	resCh := make(chan runResult, 1)
+	go func() {
+		defer lintCtx.InFlight.Start("linters", lc.Name())()
+		defer lintCtx.Tracer.Start("linter", lc.Name())()
+		defer lintCtx.Progress.StartLinter(lc.Name())()
+		issues, err := r.runLinterSafe(linterCtx, lintCtx, lc)
+		resCh <- runResult{issues: issues, err: err}
+	}()
+
+	var stopErr error
+	select {
+	case res := <-resCh:
+		return res.issues, res.err
+	case <-timeoutCh:
+		stopErr = &linterTimeoutError{timeout: timeout}
+	case <-ctx.Done():
+		stopErr = ctx.Err()
+	}
+
+	cancel()
+	select {
+	case <-resCh:
+	case <-time.After(linterStopGracePeriod):
+		r.Log.Warnf("Linter %s didn't stop in %s after the cancellation", lc.Name(), linterStopGracePeriod)
+	}
+	return nil, stopErr
+}
+
+// maxSharedLintersContexts caps the pool of shared linters contexts: every context
+// but the first one deep-copies the packages graph, the memory grows with every copy.
+// Most go/analysis linters are combined into one linter, so a few contexts are enough
+// to keep CPUs busy: packages are analyzed in parallel inside of a linter.
+const maxSharedLintersContexts = 4
+
+// makeLintersContexts returns own contexts of linters and a pool of contexts shared
+// by other linters: linters sharing a context can't run concurrently because the
+// go/analysis runner saves types and syntax into packages. The pool has a context per
+// parallel linter up to maxSharedLintersContexts, it bounds the memory used by copies
+// of packages: with more parallel linters they wait for a free context.
+// Type-changing linters (unused) always get own isolated packages: they leave dirty
+// types in packages, it affects the next analysis
+// (see https://github.com/golangci/golangci-lint/pull/944).
+// Linters with own timeouts are isolated too.
+func (r Runner) makeLintersContexts(linters []*linter.Config, lintCtx *linter.Context,
+	parallelism int) ([]*linter.Context, chan *linter.Context) {
+	lintCtxs := make([]*linter.Context, len(linters))
+	for i, lc := range linters {
+		// A timed out linter can still work in background.
+		if lc.DoesChangeTypes || lc.Timeout(r.linterTimeouts) != 0 {
+			lintCtxs[i] = lintCtx.WithIsolatedPackages()
+		}
+	}
+
+	poolSize := parallelism
+	if poolSize > maxSharedLintersContexts {
+		poolSize = maxSharedLintersContexts
+	}
+
+	sharedCtxs := make(chan *linter.Context, poolSize)
+	sharedCtxs <- lintCtx
+	for i := 1; i < poolSize; i++ {
+		sharedCtxs <- lintCtx.WithIsolatedPackages()
+	}
+
+	return lintCtxs, sharedCtxs
+}
+
+func (r *Runner) processIssues(issues []result.Issue, sw *timeutils.Stopwatch, statPerProcessor map[string]processorStat,
+	tracer *timeutils.Tracer) []result.Issue {
 	for _, p := range r.Processors {
 		var newIssues []result.Issue
 		var err error
 		p := p
+		endSpan := tracer.Start("processor", p.Name())
 		sw.TrackStage(p.Name(), func() {
 			newIssues, err = p.Process(issues)
 		})
+		endSpan()
 
 		if err != nil {
 			r.Log.Warnf("Can't process result by %s processor: %s", p.Name(), err)
@@ -211,6 +411,9 @@ func (r *Runner) processIssues(issues []result.Issue, sw *timeutils.Stopwatch, s
 			stat.inCount += len(issues)
 			stat.outCount += len(newIssues)
 			statPerProcessor[p.Name()] = stat
+			if r.explainFiltered && len(newIssues) < len(issues) {
+				r.reportFilteredIssues(p, issues, newIssues)
+			}
 			issues = newIssues
 		}
 
@@ -222,6 +425,67 @@ func (r *Runner) processIssues(issues []result.Issue, sw *timeutils.Stopwatch, s
 	return issues
 }
 
+type filteredIssueKey struct {
+	fromLinter, text, file string
+	line, column           int
+}
+
+func newFilteredIssueKey(i *result.Issue) filteredIssueKey {
+	return filteredIssueKey{
+		fromLinter: i.FromLinter,
+		text:       i.Text,
+		file:       i.FilePath(),
+		line:       i.Line(),
+		column:     i.Column(),
+	}
+}
+
+// reportFilteredIssues saves issues dropped by the processor to the report.
+// Processors can reorder issues, so dropped issues are found by counting.
+func (r *Runner) reportFilteredIssues(p processors.Processor, inIssues, outIssues []result.Issue) {
+	outCount := make(map[filteredIssueKey]int, len(outIssues))
+	for i := range outIssues {
+		outCount[newFilteredIssueKey(&outIssues[i])]++
+	}
+
+	explainer, _ := p.(processors.FilterExplainer)
+	for i := range inIssues {
+		issue := &inIssues[i]
+		key := newFilteredIssueKey(issue)
+		if outCount[key] != 0 {
+			outCount[key]--
+			continue
+		}
+
+		fi := report.FilteredIssue{
+			Issue:     *issue,
+			Processor: p.Name(),
+		}
+		if explainer != nil {
+			fi.Reason = explainer.ExplainFiltered(issue)
+		}
+		r.reportData.FilteredIssues = append(r.reportData.FilteredIssues, fi)
+	}
+}
+
+func getSkipProcessors(cfg *config.Run, log logutils.Log) (*processors.SkipFiles, *processors.SkipDirs, error) {
+	skipFilesProcessor, err := processors.NewSkipFiles(cfg.SkipFiles)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	skipDirs := append([]string{}, cfg.SkipDirs...)
+	if cfg.UseDefaultSkipDirs {
+		skipDirs = append(skipDirs, packages.StdExcludeDirRegexps...)
+	}
+	skipDirsProcessor, err := processors.NewSkipDirs(skipDirs, log.Child("skip dirs"), cfg.Args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return skipFilesProcessor, skipDirsProcessor, nil
+}
+
 func getExcludeProcessor(cfg *config.Issues) processors.Processor {
 	excludePatterns := cfg.ExcludePatterns
 	if cfg.UseDefaultExcludes {
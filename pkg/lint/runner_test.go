@@ -0,0 +1,37 @@
+package lint
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/golangci/golangci-lint/pkg/golinters"
+	"github.com/golangci/golangci-lint/pkg/lint/linter"
+)
+
+func TestMakeLintersContexts(t *testing.T) {
+	r := Runner{linterTimeouts: map[string]time.Duration{"gofmt": time.Minute}}
+	lintCtx := &linter.Context{Packages: []*packages.Package{{ID: "a"}}}
+
+	linters := []*linter.Config{
+		linter.NewConfig(golinters.NewGofmt()),
+		linter.NewConfig(golinters.NewUnused()).WithChangeTypes(),
+		linter.NewConfig(golinters.NewGovet(nil)),
+	}
+	lintCtxs, sharedCtxs := r.makeLintersContexts(linters, lintCtx, 2*maxSharedLintersContexts)
+
+	// Linters with own timeouts and type-changing linters get own copies of packages.
+	assert.NotNil(t, lintCtxs[0])
+	assert.NotNil(t, lintCtxs[1])
+	assert.Nil(t, lintCtxs[2])
+
+	// The number of copies for other linters doesn't grow with the concurrency.
+	assert.Equal(t, maxSharedLintersContexts, len(sharedCtxs))
+	assert.True(t, lintCtx == <-sharedCtxs, "the original packages are shared first")
+	for len(sharedCtxs) != 0 {
+		shared := <-sharedCtxs
+		assert.True(t, lintCtx.Packages[0] != shared.Packages[0], "packages are copied")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/golangci/golangci-lint/pkg/logutils"
 	"github.com/golangci/golangci-lint/pkg/result"
@@ -23,6 +24,29 @@ type CodeClimateIssue struct {
 	} `json:"location"`
 }
 
+const defaultCodeClimateSeverity = "major"
+
+// codeClimateSeverities maps severities of linters and other output formats to
+// the ones allowed by Code Climate: info, minor, major, critical and blocker.
+var codeClimateSeverities = map[string]string{
+	"ignore":               "info",
+	result.SeverityInfo:    "info",
+	"minor":                "minor",
+	result.SeverityWarning: "minor",
+	"major":                "major",
+	result.SeverityError:   "major",
+	"critical":             "critical",
+	"blocker":              "blocker",
+}
+
+func codeClimateSeverity(severity string) string {
+	if s, ok := codeClimateSeverities[strings.ToLower(severity)]; ok {
+		return s
+	}
+
+	return defaultCodeClimateSeverity
+}
+
 type CodeClimate struct {
 }
 
@@ -41,7 +65,7 @@ func (p CodeClimate) Print(ctx context.Context, issues []result.Issue) error {
 		codeClimateIssue.Fingerprint = issue.Fingerprint()
 
 		if issue.Severity != "" {
-			codeClimateIssue.Severity = issue.Severity
+			codeClimateIssue.Severity = codeClimateSeverity(issue.Severity)
 		}
 
 		codeClimateIssues = append(codeClimateIssues, codeClimateIssue)
@@ -0,0 +1,20 @@
+package printers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodeClimateSeverity(t *testing.T) {
+	for severity, expected := range map[string]string{
+		"info":     "info",
+		"warning":  "minor",
+		"error":    "major",
+		"Critical": "critical",
+		"blocker":  "blocker",
+		"custom":   "major",
+	} {
+		assert.Equal(t, expected, codeClimateSeverity(severity), severity)
+	}
+}
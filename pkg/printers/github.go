@@ -3,6 +3,7 @@ package printers
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/golangci/golangci-lint/pkg/logutils"
 	"github.com/golangci/golangci-lint/pkg/result"
@@ -19,12 +20,32 @@ func NewGithub() Printer {
 	return &github{}
 }
 
+// githubSeverities maps severities of linters and other output formats to Github workflow commands:
+// unknown commands are silently ignored by Github.
+var githubSeverities = map[string]string{
+	"debug":                "debug",
+	"ignore":               "notice",
+	result.SeverityInfo:    "notice",
+	"notice":               "notice",
+	"minor":                "warning",
+	result.SeverityWarning: "warning",
+	"major":                "error",
+	result.SeverityError:   "error",
+	"critical":             "error",
+	"blocker":              "error",
+}
+
+func githubSeverity(severity string) string {
+	if s, ok := githubSeverities[strings.ToLower(severity)]; ok {
+		return s
+	}
+
+	return defaultGithubSeverity
+}
+
 // print each line as: ::error file=app.js,line=10,col=15::Something went wrong
 func formatIssueAsGithub(issue *result.Issue) string {
-	severity := defaultGithubSeverity
-	if issue.Severity != "" {
-		severity = issue.Severity
-	}
+	severity := githubSeverity(issue.Severity)
 
 	ret := fmt.Sprintf("::%s file=%s,line=%d", severity, issue.FilePath(), issue.Line())
 	if issue.Pos.Column != 0 {
@@ -25,3 +25,27 @@ func TestFormatGithubIssue(t *testing.T) {
 	sampleIssue.Pos.Column = 0
 	require.Equal(t, "::error file=path/to/file.go,line=10::some issue (sample-linter)", formatIssueAsGithub(&sampleIssue))
 }
+
+func TestFormatGithubIssueSeverity(t *testing.T) {
+	sampleIssue := result.Issue{
+		FromLinter: "sample-linter",
+		Text:       "some issue",
+		Pos: token.Position{
+			Filename: "path/to/file.go",
+			Line:     10,
+		},
+	}
+
+	for severity, expected := range map[string]string{
+		"info":     "notice",
+		"warning":  "warning",
+		"minor":    "warning",
+		"major":    "error",
+		"critical": "error",
+		"custom":   "error",
+	} {
+		sampleIssue.Severity = severity
+		require.Equal(t, "::"+expected+" file=path/to/file.go,line=10::some issue (sample-linter)",
+			formatIssueAsGithub(&sampleIssue), severity)
+	}
+}
@@ -31,6 +31,7 @@ type testCaseXML struct {
 
 type failureXML struct {
 	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
 	Content string `xml:",cdata"`
 }
 
@@ -57,6 +58,7 @@ func (JunitXML) Print(ctx context.Context, issues []result.Issue) error {
 			ClassName: i.Pos.String(),
 			Failure: failureXML{
 				Message: i.Text,
+				Type:    i.Severity,
 				Content: strings.Join(i.SourceLines, "\n"),
 			},
 		}
@@ -2,6 +2,9 @@ package printers
 
 import (
 	"context"
+	"strings"
+
+	"github.com/fatih/color"
 
 	"github.com/golangci/golangci-lint/pkg/result"
 )
@@ -9,3 +12,25 @@ import (
 type Printer interface {
 	Print(ctx context.Context, issues []result.Issue) error
 }
+
+// issueTextColor returns the color of the issue text depending on its severity.
+func issueTextColor(i *result.Issue) color.Attribute {
+	switch i.Severity {
+	case result.SeverityWarning:
+		return color.FgYellow
+	case result.SeverityInfo:
+		return color.FgCyan
+	default:
+		return color.FgRed
+	}
+}
+
+// issueLinters returns the linter name of the issue followed by linters
+// whose duplicates were merged into it.
+func issueLinters(i *result.Issue) string {
+	if len(i.AlsoReportedBy) == 0 {
+		return i.FromLinter
+	}
+
+	return i.FromLinter + ", also reported by " + strings.Join(i.AlsoReportedBy, ", ")
+}
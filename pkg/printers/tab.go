@@ -44,9 +44,9 @@ func (p *Tab) Print(ctx context.Context, issues []result.Issue) error {
 }
 
 func (p Tab) printIssue(i *result.Issue, w io.Writer) {
-	text := p.SprintfColored(color.FgRed, "%s", i.Text)
+	text := p.SprintfColored(issueTextColor(i), "%s", i.Text)
 	if p.printLinterName {
-		text = fmt.Sprintf("%s\t%s", i.FromLinter, text)
+		text = fmt.Sprintf("%s\t%s", issueLinters(i), text)
 	}
 
 	pos := p.SprintfColored(color.Bold, "%s:%d", i.FilePath(), i.Line())
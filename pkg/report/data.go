@@ -1,5 +1,11 @@
 package report
 
+import (
+	"sync"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
 type Warning struct {
 	Tag  string `json:",omitempty"`
 	Text string
@@ -9,12 +15,38 @@ type LinterData struct {
 	Name             string
 	Enabled          bool `json:",omitempty"`
 	EnabledByDefault bool `json:",omitempty"`
+
+	// Failed linters (e.g. timed out) don't report issues, Error is the reason
+	Failed bool   `json:",omitempty"`
+	Error  string `json:",omitempty"`
+
+	// Unfinished linters didn't finish before the run timeout, see Data.Partial
+	Unfinished bool `json:",omitempty"`
+}
+
+// FilteredIssue is an issue dropped by a processor, it's reported only with --explain-filtered.
+type FilteredIssue struct {
+	Issue     result.Issue
+	Processor string
+	Reason    string `json:",omitempty"`
 }
 
 type Data struct {
-	Warnings []Warning    `json:",omitempty"`
-	Linters  []LinterData `json:",omitempty"`
-	Error    string       `json:",omitempty"`
+	Warnings       []Warning       `json:",omitempty"`
+	Linters        []LinterData    `json:",omitempty"`
+	FilteredIssues []FilteredIssue `json:",omitempty"`
+	Error          string          `json:",omitempty"`
+
+	// Partial is set when the run was timed out: only issues of linters
+	// finished before the timeout are reported
+	Partial bool `json:",omitempty"`
+
+	// FailThresholdReached is set when an issue at least as severe as severity.fail-threshold
+	// was found, even if it was hidden by issue limits
+	FailThresholdReached bool `json:"-"`
+
+	// linters run concurrently and log warnings at the same time
+	mu sync.Mutex
 }
 
 func (d *Data) AddLinter(name string, enabled, enabledByDefault bool) {
@@ -24,3 +56,36 @@ func (d *Data) AddLinter(name string, enabled, enabledByDefault bool) {
 		EnabledByDefault: enabledByDefault,
 	})
 }
+
+// AddLinterFailure marks the linter as failed.
+func (d *Data) AddLinterFailure(name string, err error) {
+	d.addLinterFailure(name, err, false)
+}
+
+// AddUnfinishedLinter marks the linter as failed because of the run timeout.
+func (d *Data) AddUnfinishedLinter(name string, err error) {
+	d.addLinterFailure(name, err, true)
+}
+
+func (d *Data) addLinterFailure(name string, err error, unfinished bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for i := range d.Linters {
+		if d.Linters[i].Name == name {
+			d.Linters[i].Failed = true
+			d.Linters[i].Error = err.Error()
+			d.Linters[i].Unfinished = unfinished
+			return
+		}
+	}
+
+	// e.g. goanalysis_metalinter isn't in the list of supported linters
+	d.Linters = append(d.Linters, LinterData{
+		Name:       name,
+		Enabled:    true,
+		Failed:     true,
+		Error:      err.Error(),
+		Unfinished: unfinished,
+	})
+}
@@ -30,6 +30,9 @@ func (lw LogWrapper) Panicf(format string, args ...interface{}) {
 
 func (lw LogWrapper) Errorf(format string, args ...interface{}) {
 	lw.origLog.Errorf(format, args...)
+
+	lw.rd.mu.Lock()
+	defer lw.rd.mu.Unlock()
 	lw.rd.Error = fmt.Sprintf(format, args...)
 }
 
@@ -40,6 +43,8 @@ func (lw LogWrapper) Warnf(format string, args ...interface{}) {
 		Text: fmt.Sprintf(format, args...),
 	}
 
+	lw.rd.mu.Lock()
+	defer lw.rd.mu.Unlock()
 	lw.rd.Warnings = append(lw.rd.Warnings, w)
 }
 
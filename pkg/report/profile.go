@@ -0,0 +1,216 @@
+package report
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/golangci/golangci-lint/pkg/timeutils"
+)
+
+// Profile collects timings, cache and memory stats of the run to write them by --profile-report.
+// A nil *Profile doesn't collect anything.
+type Profile struct {
+	startedAt time.Time
+
+	mu                   sync.Mutex
+	stagesStopwatches    map[string]*timeutils.Stopwatch
+	lintersStopwatch     *timeutils.Stopwatch
+	analyzersStopwatches map[string]*timeutils.Stopwatch
+	packages             []PackageTiming
+	cache                map[string]*CacheStats
+	memory               MemoryStats
+}
+
+type StageTiming struct {
+	Name     string
+	Duration time.Duration
+}
+
+type AnalyzerTiming struct {
+	Linter   string
+	Name     string
+	Duration time.Duration
+}
+
+// PackageTiming is a time of loading and analyzing the package by the linter.
+type PackageTiming struct {
+	Linter   string
+	Package  string
+	Duration time.Duration
+}
+
+type CacheStats struct {
+	Hits   int
+	Misses int
+}
+
+type MemoryStats struct {
+	Samples   int
+	AvgRSSMB  float64
+	MaxRSSMB  float64
+	MaxHeapMB float64
+}
+
+// ProfileReport is written in JSON, all durations are in nanoseconds.
+type ProfileReport struct {
+	Duration  time.Duration
+	Stages    map[string][]StageTiming `json:",omitempty"`
+	Linters   []StageTiming            `json:",omitempty"`
+	Analyzers []AnalyzerTiming         `json:",omitempty"`
+	Packages  []PackageTiming          `json:",omitempty"`
+	Cache     map[string]CacheStats    `json:",omitempty"`
+	Memory    MemoryStats
+}
+
+func NewProfile() *Profile {
+	return &Profile{
+		startedAt:            time.Now(),
+		stagesStopwatches:    map[string]*timeutils.Stopwatch{},
+		analyzersStopwatches: map[string]*timeutils.Stopwatch{},
+		cache:                map[string]*CacheStats{},
+	}
+}
+
+// AddStagesStopwatch adds stages of a phase of the run, e.g. packages loading or issues processing.
+func (p *Profile) AddStagesStopwatch(phase string, sw *timeutils.Stopwatch) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stagesStopwatches[phase] = sw
+}
+
+// AddLintersStopwatch adds the stopwatch having a stage per linter.
+func (p *Profile) AddLintersStopwatch(sw *timeutils.Stopwatch) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.lintersStopwatch = sw
+}
+
+// AddAnalyzersStopwatch adds the stopwatch having a stage per analyzer of the linter.
+func (p *Profile) AddAnalyzersStopwatch(linterName string, sw *timeutils.Stopwatch) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.analyzersStopwatches[linterName] = sw
+}
+
+func (p *Profile) AddPackage(linterName, pkgPath string, d time.Duration) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.packages = append(p.packages, PackageTiming{Linter: linterName, Package: pkgPath, Duration: d})
+}
+
+// AddCacheLookup counts a cache hit or miss for the kind of cached data, e.g. facts.
+func (p *Profile) AddCacheLookup(kind string, hit bool) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := p.cache[kind]
+	if stats == nil {
+		stats = &CacheStats{}
+		p.cache[kind] = stats
+	}
+	if hit {
+		stats.Hits++
+	} else {
+		stats.Misses++
+	}
+}
+
+func (p *Profile) SetMemory(stats MemoryStats) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.memory = stats
+}
+
+func (p *Profile) Report() *ProfileReport {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ret := &ProfileReport{
+		Duration: time.Since(p.startedAt),
+		Stages:   map[string][]StageTiming{},
+		Cache:    map[string]CacheStats{},
+		Memory:   p.memory,
+	}
+
+	for phase, sw := range p.stagesStopwatches {
+		ret.Stages[phase] = stageTimings(sw)
+	}
+
+	if p.lintersStopwatch != nil {
+		ret.Linters = stageTimings(p.lintersStopwatch)
+	}
+
+	for linterName, sw := range p.analyzersStopwatches {
+		for _, st := range stageTimings(sw) {
+			ret.Analyzers = append(ret.Analyzers, AnalyzerTiming{Linter: linterName, Name: st.Name, Duration: st.Duration})
+		}
+	}
+	sort.Slice(ret.Analyzers, func(i, j int) bool {
+		return ret.Analyzers[i].Duration > ret.Analyzers[j].Duration
+	})
+
+	ret.Packages = append(ret.Packages, p.packages...)
+	sort.Slice(ret.Packages, func(i, j int) bool {
+		return ret.Packages[i].Duration > ret.Packages[j].Duration
+	})
+
+	for kind, stats := range p.cache {
+		ret.Cache[kind] = *stats
+	}
+
+	return ret
+}
+
+func (p *Profile) Write(path string) error {
+	data, err := json.MarshalIndent(p.Report(), "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal profile report")
+	}
+
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		return errors.Wrapf(err, "failed to write profile report to %s", path)
+	}
+
+	return nil
+}
+
+func stageTimings(sw *timeutils.Stopwatch) []StageTiming {
+	stages := sw.Stages()
+	ret := make([]StageTiming, 0, len(stages))
+	for name, d := range stages {
+		ret = append(ret, StageTiming{Name: name, Duration: d})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Duration > ret[j].Duration
+	})
+	return ret
+}
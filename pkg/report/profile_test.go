@@ -0,0 +1,137 @@
+package report
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/golangci/golangci-lint/pkg/logutils"
+	"github.com/golangci/golangci-lint/pkg/timeutils"
+)
+
+func newTestStopwatch(stages map[string][]time.Duration) *timeutils.Stopwatch {
+	sw := timeutils.NewStopwatch("test", logutils.NewStderrLog(""))
+	for name, durations := range stages {
+		for _, d := range durations {
+			sw.TrackStage(name, func() { time.Sleep(d) }) //nolint:scopelint
+		}
+	}
+	return sw
+}
+
+func stageNames(timings []StageTiming) []string {
+	var names []string
+	for _, t := range timings {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+func TestProfileLinters(t *testing.T) {
+	p := NewProfile()
+	p.AddLintersStopwatch(newTestStopwatch(map[string][]time.Duration{
+		"govet":  {10 * time.Millisecond, 10 * time.Millisecond}, // e.g. in two runners
+		"unused": {30 * time.Millisecond},
+		"gofmt":  {time.Millisecond},
+	}))
+	p.AddStagesStopwatch("loading", newTestStopwatch(map[string][]time.Duration{"go list": {time.Millisecond}}))
+
+	r := p.Report()
+	require.Len(t, r.Linters, 3)
+	assert.Equal(t, []string{"unused", "govet", "gofmt"}, stageNames(r.Linters))
+	assert.True(t, r.Linters[1].Duration >= 20*time.Millisecond, "durations of a linter are summed up")
+	assert.Equal(t, []string{"go list"}, stageNames(r.Stages["loading"]))
+}
+
+func TestProfileAnalyzers(t *testing.T) {
+	p := NewProfile()
+	p.AddAnalyzersStopwatch("govet", newTestStopwatch(map[string][]time.Duration{
+		"printf": {20 * time.Millisecond},
+		"shadow": {time.Millisecond},
+	}))
+	p.AddAnalyzersStopwatch("staticcheck", newTestStopwatch(map[string][]time.Duration{
+		"SA4006": {10 * time.Millisecond},
+	}))
+
+	r := p.Report()
+	require.Len(t, r.Analyzers, 3)
+	// Analyzers of all linters are sorted together from the slowest one.
+	assert.Equal(t, "govet", r.Analyzers[0].Linter)
+	assert.Equal(t, "printf", r.Analyzers[0].Name)
+	assert.Equal(t, "staticcheck", r.Analyzers[1].Linter)
+	assert.Equal(t, "SA4006", r.Analyzers[1].Name)
+	assert.Equal(t, "shadow", r.Analyzers[2].Name)
+}
+
+func TestProfilePackages(t *testing.T) {
+	p := NewProfile()
+	p.AddPackage("govet", "a", time.Second)
+	p.AddPackage("govet", "b", 3*time.Second)
+	p.AddPackage("unused", "a", 2*time.Second)
+
+	assert.Equal(t, []PackageTiming{
+		{Linter: "govet", Package: "b", Duration: 3 * time.Second},
+		{Linter: "unused", Package: "a", Duration: 2 * time.Second},
+		{Linter: "govet", Package: "a", Duration: time.Second},
+	}, p.Report().Packages)
+}
+
+func TestProfileCache(t *testing.T) {
+	p := NewProfile()
+	p.AddCacheLookup("facts", true)
+	p.AddCacheLookup("facts", true)
+	p.AddCacheLookup("facts", false)
+	p.AddCacheLookup("packages", false)
+
+	assert.Equal(t, map[string]CacheStats{
+		"facts":    {Hits: 2, Misses: 1},
+		"packages": {Misses: 1},
+	}, p.Report().Cache)
+}
+
+func TestProfileWrite(t *testing.T) {
+	p := NewProfile()
+	p.AddLintersStopwatch(newTestStopwatch(map[string][]time.Duration{"govet": {time.Millisecond}}))
+	p.AddPackage("govet", "a", time.Second)
+	p.AddCacheLookup("facts", true)
+	p.SetMemory(MemoryStats{Samples: 2, AvgRSSMB: 10, MaxRSSMB: 12, MaxHeapMB: 8})
+
+	dir, err := ioutil.TempDir("", "golangci-lint-profile-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "profile.json")
+	require.NoError(t, p.Write(path))
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	var r map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &r))
+
+	// Durations are in nanoseconds, empty sections are omitted.
+	assert.IsType(t, float64(0), r["Duration"])
+	assert.NotContains(t, r, "Analyzers")
+	assert.Len(t, r["Linters"], 1)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"Linter": "govet", "Package": "a", "Duration": float64(time.Second)},
+	}, r["Packages"])
+	assert.Equal(t, map[string]interface{}{
+		"facts": map[string]interface{}{"Hits": float64(1), "Misses": float64(0)},
+	}, r["Cache"])
+	assert.Equal(t, map[string]interface{}{
+		"Samples": float64(2), "AvgRSSMB": float64(10), "MaxRSSMB": float64(12), "MaxHeapMB": float64(8),
+	}, r["Memory"])
+}
+
+func TestNilProfile(t *testing.T) {
+	var p *Profile
+	p.AddPackage("govet", "a", time.Second)
+	p.AddCacheLookup("facts", true)
+	p.SetMemory(MemoryStats{})
+	p.AddLintersStopwatch(nil)
+}
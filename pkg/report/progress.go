@@ -0,0 +1,229 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	progressBarWidth = 30
+
+	// Progress is redrawn often on terminals, in CI logs it's a line per interval.
+	progressTTYInterval = 200 * time.Millisecond
+	progressLogInterval = 10 * time.Second
+)
+
+// ProgressEvent is written as a JSON line for tooling by --progress-events.
+type ProgressEvent struct {
+	Time    time.Time
+	Event   string
+	Linter  string `json:",omitempty"`
+	Package string `json:",omitempty"`
+	Count   int    `json:",omitempty"`
+}
+
+const (
+	ProgressLinterStarted   = "linter_started"
+	ProgressLinterFinished  = "linter_finished"
+	ProgressPackagesAdded   = "packages_added"
+	ProgressPackagesCached  = "packages_cached"
+	ProgressPackageLoaded   = "package_loaded"
+	ProgressPackageAnalyzed = "package_analyzed"
+)
+
+// Progress reports how many packages are loaded, analyzed and taken from the cache
+// out of the total by all linters and which linters are running.
+// A nil *Progress doesn't report anything.
+type Progress struct {
+	out    io.Writer // nil if only events are written
+	isTTY  bool
+	events *json.Encoder
+
+	mu             sync.Mutex
+	total          int
+	loaded         int
+	analyzed       int
+	cached         int
+	runningLinters []string
+	lastLineLen    int
+
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+func NewProgress(out io.Writer, isTTY bool, events io.Writer) *Progress {
+	p := &Progress{
+		out:    out,
+		isTTY:  isTTY,
+		stopCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+	}
+	if events != nil {
+		p.events = json.NewEncoder(events)
+	}
+	return p
+}
+
+// Start prints the progress periodically until Stop is called.
+func (p *Progress) Start() {
+	if p == nil {
+		return
+	}
+
+	go func() {
+		defer close(p.doneCh)
+		if p.out == nil {
+			return
+		}
+
+		interval := progressLogInterval
+		if p.isTTY {
+			interval = progressTTYInterval
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-p.stopCh:
+				p.print(true)
+				return
+			case <-ticker.C:
+				p.print(false)
+			}
+		}
+	}()
+}
+
+func (p *Progress) Stop() {
+	if p == nil {
+		return
+	}
+
+	close(p.stopCh)
+	<-p.doneCh
+}
+
+// StartLinter marks the linter as running until the returned function is called.
+func (p *Progress) StartLinter(name string) (done func()) {
+	if p == nil {
+		return func() {}
+	}
+
+	p.mu.Lock()
+	p.runningLinters = append(p.runningLinters, name)
+	p.writeEvent(ProgressEvent{Event: ProgressLinterStarted, Linter: name})
+	p.mu.Unlock()
+
+	return func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		for i, n := range p.runningLinters {
+			if n == name {
+				p.runningLinters = append(p.runningLinters[:i], p.runningLinters[i+1:]...)
+				break
+			}
+		}
+		p.writeEvent(ProgressEvent{Event: ProgressLinterFinished, Linter: name})
+	}
+}
+
+// AddPackages adds packages the linter analyzes to the total,
+// cachedCount of them are taken from the cache.
+func (p *Progress) AddPackages(linterName string, count, cachedCount int) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.total += count
+	p.cached += cachedCount
+	p.writeEvent(ProgressEvent{Event: ProgressPackagesAdded, Linter: linterName, Count: count})
+	if cachedCount != 0 {
+		p.writeEvent(ProgressEvent{Event: ProgressPackagesCached, Linter: linterName, Count: cachedCount})
+	}
+}
+
+func (p *Progress) PackageLoaded(linterName, pkgPath string) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.loaded++
+	p.writeEvent(ProgressEvent{Event: ProgressPackageLoaded, Linter: linterName, Package: pkgPath})
+}
+
+func (p *Progress) PackageAnalyzed(linterName, pkgPath string) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.analyzed++
+	p.writeEvent(ProgressEvent{Event: ProgressPackageAnalyzed, Linter: linterName, Package: pkgPath})
+}
+
+func (p *Progress) writeEvent(ev ProgressEvent) {
+	if p.events == nil {
+		return
+	}
+
+	ev.Time = time.Now()
+	_ = p.events.Encode(ev) // progress events are best effort
+}
+
+func (p *Progress) print(isFinal bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	line := p.sprintLine()
+	if !p.isTTY {
+		fmt.Fprintln(p.out, line)
+		return
+	}
+
+	// Redraw the line: pad it to erase the previous longer one.
+	padding := ""
+	if len(line) < p.lastLineLen {
+		padding = strings.Repeat(" ", p.lastLineLen-len(line))
+	}
+	p.lastLineLen = len(line)
+	fmt.Fprintf(p.out, "\r%s%s", line, padding)
+	if isFinal {
+		fmt.Fprintln(p.out)
+	}
+}
+
+func (p *Progress) sprintLine() string {
+	done := p.analyzed + p.cached
+	percent := 0
+	if p.total != 0 {
+		percent = done * 100 / p.total
+	}
+	if percent > 100 { // a linter can add packages after others finished
+		percent = 100
+	}
+
+	line := fmt.Sprintf("Progress: %3d%% packages %d/%d: loaded %d, analyzed %d, cached %d",
+		percent, done, p.total, p.loaded, p.analyzed, p.cached)
+	if p.isTTY {
+		filled := percent * progressBarWidth / 100
+		line = fmt.Sprintf("[%s%s] %s", strings.Repeat("=", filled), strings.Repeat(" ", progressBarWidth-filled), line)
+	}
+	if len(p.runningLinters) != 0 {
+		line += "; running " + strings.Join(p.runningLinters, ", ")
+	}
+	return line
+}
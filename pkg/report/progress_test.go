@@ -0,0 +1,127 @@
+package report
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runLinters reports two linters: govet has 1 of 4 packages cached and analyzes 2 of 3 others,
+// unused has its only package cached. govet is running until doneGovet is called.
+func runLinters(p *Progress) (doneGovet func()) {
+	doneGovet = p.StartLinter("govet")
+	p.AddPackages("govet", 4, 1)
+	for _, pkg := range []string{"a", "b", "c"} {
+		p.PackageLoaded("govet", pkg)
+	}
+	p.PackageAnalyzed("govet", "a")
+	p.PackageAnalyzed("govet", "b")
+
+	doneUnused := p.StartLinter("unused")
+	p.AddPackages("unused", 1, 1)
+	doneUnused()
+	return doneGovet
+}
+
+func TestProgressLog(t *testing.T) {
+	var out bytes.Buffer
+	p := NewProgress(&out, false, nil)
+	runLinters(p)
+
+	// A line per interval: the counters and running linters.
+	p.print(false)
+	assert.Equal(t, "Progress:  80% packages 4/5: loaded 3, analyzed 2, cached 2; running govet\n", out.String())
+
+	p.PackageAnalyzed("govet", "c")
+	p.print(false)
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, "Progress: 100% packages 5/5: loaded 3, analyzed 3, cached 2; running govet", lines[1])
+}
+
+func TestProgressStop(t *testing.T) {
+	var out bytes.Buffer
+	p := NewProgress(&out, false, nil)
+	p.Start()
+	done := p.StartLinter("govet")
+	p.AddPackages("govet", 2, 0)
+	p.PackageLoaded("govet", "a")
+	p.PackageAnalyzed("govet", "a")
+	done()
+	p.Stop()
+
+	// The final line is printed on stop, long before the log interval.
+	assert.Equal(t, "Progress:  50% packages 1/2: loaded 1, analyzed 1, cached 0\n", out.String())
+}
+
+func TestProgressTTY(t *testing.T) {
+	var out bytes.Buffer
+	p := NewProgress(&out, true, nil)
+	done := p.StartLinter("govet")
+	p.AddPackages("govet", 2, 1)
+	p.print(false)
+	done()
+	p.print(true)
+
+	bar := "[" + strings.Repeat("=", progressBarWidth/2) + strings.Repeat(" ", progressBarWidth/2) + "] "
+	first := bar + "Progress:  50% packages 1/2: loaded 0, analyzed 0, cached 1; running govet"
+	second := bar + "Progress:  50% packages 1/2: loaded 0, analyzed 0, cached 1"
+
+	// The line is redrawn and padded to erase the previous longer one.
+	assert.Equal(t, "\r"+first+"\r"+second+strings.Repeat(" ", len(first)-len(second))+"\n", out.String())
+}
+
+func TestProgressEvents(t *testing.T) {
+	var events bytes.Buffer
+	p := NewProgress(nil, false, &events)
+	p.Start()
+	runLinters(p)()
+	p.Stop()
+
+	var got []ProgressEvent
+	scanner := bufio.NewScanner(&events)
+	for scanner.Scan() {
+		var ev map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &ev), "every line is a JSON event")
+		assert.Contains(t, ev, "Time")
+
+		var pev ProgressEvent
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &pev))
+		assert.False(t, pev.Time.IsZero())
+		pev.Time = time.Time{}
+		got = append(got, pev)
+	}
+	require.NoError(t, scanner.Err())
+
+	assert.Equal(t, []ProgressEvent{
+		{Event: ProgressLinterStarted, Linter: "govet"},
+		{Event: ProgressPackagesAdded, Linter: "govet", Count: 4},
+		{Event: ProgressPackagesCached, Linter: "govet", Count: 1},
+		{Event: ProgressPackageLoaded, Linter: "govet", Package: "a"},
+		{Event: ProgressPackageLoaded, Linter: "govet", Package: "b"},
+		{Event: ProgressPackageLoaded, Linter: "govet", Package: "c"},
+		{Event: ProgressPackageAnalyzed, Linter: "govet", Package: "a"},
+		{Event: ProgressPackageAnalyzed, Linter: "govet", Package: "b"},
+		{Event: ProgressLinterStarted, Linter: "unused"},
+		{Event: ProgressPackagesAdded, Linter: "unused", Count: 1},
+		{Event: ProgressPackagesCached, Linter: "unused", Count: 1},
+		{Event: ProgressLinterFinished, Linter: "unused"},
+		{Event: ProgressLinterFinished, Linter: "govet"},
+	}, got)
+}
+
+func TestNilProgress(t *testing.T) {
+	var p *Progress
+	p.Start()
+	p.StartLinter("govet")()
+	p.AddPackages("govet", 1, 1)
+	p.PackageLoaded("govet", "a")
+	p.PackageAnalyzed("govet", "a")
+	p.Stop()
+}
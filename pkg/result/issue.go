@@ -28,7 +28,11 @@ type Issue struct {
 	FromLinter string
 	Text       string
 
-	Severity string
+	// AlsoReportedBy lists other linters that reported the same problem at the same line
+	AlsoReportedBy []string `json:",omitempty"`
+
+	Severity   string
+	Confidence string `json:",omitempty"`
 
 	// Source lines of a code with the issue to show
 	SourceLines []string
@@ -41,6 +41,10 @@ func (p *AutogeneratedExclude) Process(issues []result.Issue) ([]result.Issue, e
 	return filterIssuesErr(issues, p.shouldPassIssue)
 }
 
+func (p *AutogeneratedExclude) ExplainFiltered(i *result.Issue) string {
+	return "file is autogenerated"
+}
+
 func isSpecialAutogeneratedFile(filePath string) bool {
 	fileName := filepath.Base(filePath)
 	// fake files to which //line points to for goyacc generated files
@@ -55,4 +55,8 @@ func (p Cgo) Process(issues []result.Issue) ([]result.Issue, error) {
 	})
 }
 
+func (Cgo) ExplainFiltered(i *result.Issue) string {
+	return "issue is in cgo generated file"
+}
+
 func (Cgo) Finish() {}
@@ -1,59 +1,82 @@
 package processors
 
 import (
+	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
-	"io"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"io/ioutil"
 	"os"
+	"os/exec"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/golangci/revgrep"
 
+	"github.com/golangci/golangci-lint/pkg/config"
 	"github.com/golangci/golangci-lint/pkg/result"
 )
 
 type Diff struct {
 	onlyNew       bool
 	fromRev       string
+	fromMergeBase string
 	patchFilePath string
 	patch         string
+	granularity   string
+
+	// file path -> line ranges of functions declared in the file
+	funcRanges map[string][]result.Range
+
+	// The patch is read and parsed once: it's shared by the filtering of unchanged
+	// packages before the analysis and by the processing of issues.
+	patchLoaded bool
+	patchErr    error
+	patchData   []byte
+	newFiles    []string
+
+	// file path -> changes of the file in the patch, used to explain filtered issues
+	changes map[string]*fileChanges
 }
 
-var _ Processor = Diff{}
+var _ Processor = &Diff{}
 
-func NewDiff(onlyNew bool, fromRev, patchFilePath string) *Diff {
+func NewDiff(cfg *config.Issues) *Diff {
 	return &Diff{
-		onlyNew:       onlyNew,
-		fromRev:       fromRev,
-		patchFilePath: patchFilePath,
+		onlyNew:       cfg.Diff,
+		fromRev:       cfg.DiffFromRevision,
+		fromMergeBase: cfg.DiffFromMergeBase,
+		patchFilePath: cfg.DiffPatchFilePath,
 		patch:         os.Getenv("GOLANGCI_DIFF_PROCESSOR_PATCH"),
+		granularity:   cfg.DiffGranularity,
 	}
 }
 
-func (p Diff) Name() string {
+func (p *Diff) Name() string {
 	return "diff"
 }
 
-func (p Diff) Process(issues []result.Issue) ([]result.Issue, error) {
-	if !p.onlyNew && p.fromRev == "" && p.patchFilePath == "" && p.patch == "" { // no need to work
+// Enabled reports whether only issues from the diff are shown.
+func (p *Diff) Enabled() bool {
+	return p.onlyNew || p.fromRev != "" || p.fromMergeBase != "" || p.patchFilePath != "" || p.patch != ""
+}
+
+func (p *Diff) Process(issues []result.Issue) ([]result.Issue, error) {
+	if !p.Enabled() { // no need to work
 		return issues, nil
 	}
 
-	var patchReader io.Reader
-	if p.patchFilePath != "" {
-		patch, err := ioutil.ReadFile(p.patchFilePath)
-		if err != nil {
-			return nil, fmt.Errorf("can't read from patch file %s: %s", p.patchFilePath, err)
-		}
-		patchReader = bytes.NewReader(patch)
-	} else if p.patch != "" {
-		patchReader = strings.NewReader(p.patch)
+	if err := p.loadPatch(); err != nil {
+		return nil, err
 	}
 
 	c := revgrep.Checker{
-		Patch:        patchReader,
-		RevisionFrom: p.fromRev,
+		Patch:    bytes.NewReader(p.patchData),
+		NewFiles: p.newFiles,
 	}
 	if err := c.Prepare(); err != nil {
 		return nil, fmt.Errorf("can't prepare diff by revgrep: %s", err)
@@ -61,7 +84,7 @@ func (p Diff) Process(issues []result.Issue) ([]result.Issue, error) {
 
 	return transformIssues(issues, func(i *result.Issue) *result.Issue {
 		hunkPos, isNew := c.IsNewIssue(i)
-		if !isNew {
+		if !isNew && !p.isInChangedBlock(i) {
 			return nil
 		}
 
@@ -71,4 +94,299 @@ func (p Diff) Process(issues []result.Issue) ([]result.Issue, error) {
 	}), nil
 }
 
-func (Diff) Finish() {}
+// isInChangedBlock reports whether the issue is in a changed hunk or function
+// depending on the granularity.
+func (p *Diff) isInChangedBlock(i *result.Issue) bool {
+	fc := p.changes[i.FilePath()]
+	if fc == nil {
+		return false
+	}
+
+	switch p.granularity {
+	case config.DiffGranularityHunk:
+		for _, h := range fc.hunks {
+			if h.From <= i.Line() && i.Line() <= h.To {
+				return true
+			}
+		}
+	case config.DiffGranularityFunction:
+		for _, fr := range p.getFuncRanges(i.FilePath()) {
+			if i.Line() < fr.From || fr.To < i.Line() {
+				continue
+			}
+
+			for _, line := range fc.touchedLines {
+				if fr.From <= line && line <= fr.To {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+func (p *Diff) getFuncRanges(filePath string) []result.Range {
+	if p.funcRanges == nil {
+		p.funcRanges = map[string][]result.Range{}
+	}
+	if ranges, ok := p.funcRanges[filePath]; ok {
+		return ranges
+	}
+
+	var ranges []result.Range
+	fset := token.NewFileSet()
+	// partially parsed file is enough: issues in unparsed functions stay filtered out
+	f, _ := parser.ParseFile(fset, filePath, nil, 0)
+	if f != nil {
+		for _, decl := range f.Decls {
+			if fd, ok := decl.(*ast.FuncDecl); ok {
+				ranges = append(ranges, result.Range{
+					From: fset.Position(fd.Pos()).Line,
+					To:   fset.Position(fd.End()).Line,
+				})
+			}
+		}
+	}
+
+	p.funcRanges[filePath] = ranges
+	return ranges
+}
+
+func (p *Diff) loadPatch() error {
+	if p.patchLoaded {
+		return p.patchErr
+	}
+	p.patchLoaded = true
+
+	p.patchData, p.newFiles, p.patchErr = p.readPatch()
+	if p.patchErr == nil {
+		p.changes, p.patchErr = parsePatch(p.patchData)
+	}
+	return p.patchErr
+}
+
+func (p *Diff) readPatch() (patch []byte, newFiles []string, err error) {
+	switch {
+	case p.patchFilePath != "":
+		patch, err = ioutil.ReadFile(p.patchFilePath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("can't read from patch file %s: %s", p.patchFilePath, err)
+		}
+		return patch, nil, nil
+	case p.patch != "":
+		return []byte(p.patch), nil, nil
+	}
+
+	revision, err := p.getRevision()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	patchReader, newFiles, err := revgrep.GitPatch(revision, "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read git repo: %s", err)
+	}
+	if patchReader == nil {
+		return nil, nil, errors.New("no version control repository found")
+	}
+
+	patch, err = ioutil.ReadAll(patchReader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("can't read git patch: %s", err)
+	}
+
+	return patch, newFiles, nil
+}
+
+func (p *Diff) getRevision() (string, error) {
+	if p.fromMergeBase == "" {
+		return p.fromRev, nil
+	}
+
+	out, err := exec.Command("git", "merge-base", p.fromMergeBase, "HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("can't find merge base of %s and HEAD: %s", p.fromMergeBase, err)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// ChangedFiles returns paths of files changed in the diff, relative to the current work dir.
+// Only packages containing these files need to be analyzed.
+func (p *Diff) ChangedFiles() ([]string, error) {
+	if err := p.loadPatch(); err != nil {
+		return nil, err
+	}
+
+	files := append([]string{}, p.newFiles...)
+	for file := range p.changes {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
+
+func (p *Diff) ExplainFiltered(i *result.Issue) string {
+	var where string
+	switch {
+	case p.fromRev != "":
+		where = "since revision " + p.fromRev
+	case p.fromMergeBase != "":
+		where = "since the merge base with " + p.fromMergeBase
+	case p.patchFilePath != "":
+		where = "in patch " + p.patchFilePath
+	default:
+		where = "in the diff"
+	}
+
+	hunk := p.nearestHunk(i)
+	if hunk == "" {
+		return "file isn't changed " + where
+	}
+
+	what := "line isn't changed"
+	switch p.granularity {
+	case config.DiffGranularityHunk:
+		what = "line isn't in a changed hunk"
+	case config.DiffGranularityFunction:
+		what = "line isn't changed and isn't in a changed function"
+	}
+	return fmt.Sprintf("%s %s, nearest hunk is %s", what, where, hunk)
+}
+
+// nearestHunk returns the header of the hunk of the issue file nearest to the issue line
+// or an empty string if the file isn't in the diff.
+func (p *Diff) nearestHunk(i *result.Issue) string {
+	fc := p.changes[i.FilePath()]
+	if fc == nil {
+		return ""
+	}
+
+	nearest, minDist := "", 0
+	for idx, h := range fc.hunks {
+		dist := 0
+		if i.Line() < h.From {
+			dist = h.From - i.Line()
+		} else if i.Line() > h.To {
+			dist = i.Line() - h.To
+		}
+		if nearest == "" || dist < minDist {
+			nearest, minDist = fc.hunkHeaders[idx], dist
+		}
+	}
+	return nearest
+}
+
+func (p *Diff) Finish() {}
+
+type fileChanges struct {
+	// line ranges of hunks in the new version of the file
+	hunks []result.Range
+
+	// headers like "@@ -1,3 +1,4 @@" of the hunks
+	hunkHeaders []string
+
+	// added lines and lines next to deleted ones in the new version of the file
+	touchedLines []int
+}
+
+// parsePatch parses unified diff into changes by file paths from "+++ b/path" lines.
+func parsePatch(patch []byte) (map[string]*fileChanges, error) {
+	changes := map[string]*fileChanges{}
+
+	var fc *fileChanges
+	var line, oldLeft, newLeft int
+	scanner := bufio.NewScanner(bytes.NewReader(patch))
+	for scanner.Scan() {
+		text := scanner.Text()
+		inHunk := oldLeft > 0 || newLeft > 0
+
+		switch {
+		case !inHunk && strings.HasPrefix(text, "+++ "):
+			fc = nil
+			if text == "+++ /dev/null" || len(text) <= len("+++ b/") {
+				continue // deleted file
+			}
+
+			fc = &fileChanges{}
+			changes[text[len("+++ b/"):]] = fc
+		case !inHunk && strings.HasPrefix(text, "@@ "):
+			if fc == nil {
+				continue
+			}
+
+			var err error
+			var from, count int
+			oldLeft, from, count, err = parseHunkHeader(text)
+			if err != nil {
+				return nil, err
+			}
+			line, newLeft = from, count
+			if count == 0 {
+				count = 1 // pure deletion: the hunk is between lines
+			}
+			fc.hunks = append(fc.hunks, result.Range{From: from, To: from + count - 1})
+			fc.hunkHeaders = append(fc.hunkHeaders, strings.Join(strings.Fields(text)[:3], " ")+" @@")
+		case !inHunk:
+			continue
+		case strings.HasPrefix(text, "+"):
+			fc.touchedLines = append(fc.touchedLines, line)
+			line++
+			newLeft--
+		case strings.HasPrefix(text, "-"):
+			fc.touchedLines = append(fc.touchedLines, line)
+			oldLeft--
+		case strings.HasPrefix(text, `\`):
+			// "\ No newline at end of file"
+		default:
+			line++
+			oldLeft--
+			newLeft--
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("can't read patch: %s", err)
+	}
+
+	return changes, nil
+}
+
+// parseHunkHeader parses "@@ -oldFrom,oldCount +newFrom,newCount @@".
+func parseHunkHeader(header string) (oldCount, newFrom, newCount int, err error) {
+	fields := strings.Fields(header)
+	if len(fields) < 3 {
+		return 0, 0, 0, fmt.Errorf("invalid hunk header %q", header)
+	}
+
+	_, oldCount, err = parseHunkRange(strings.TrimPrefix(fields[1], "-"))
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid hunk header %q: %s", header, err)
+	}
+
+	newFrom, newCount, err = parseHunkRange(strings.TrimPrefix(fields[2], "+"))
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid hunk header %q: %s", header, err)
+	}
+
+	return oldCount, newFrom, newCount, nil
+}
+
+func parseHunkRange(r string) (from, count int, err error) {
+	parts := strings.SplitN(r, ",", 2)
+	from, err = strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	count = 1
+	if len(parts) == 2 {
+		count, err = strconv.Atoi(parts[1])
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	return from, count, nil
+}
@@ -0,0 +1,198 @@
+package processors
+
+import (
+	"go/token"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/golangci/golangci-lint/pkg/config"
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+const testPatch = `diff --git a/a/a.go b/a/a.go
+index 8fd8b0a..c0e8f6e 100644
+--- a/a/a.go
++++ b/a/a.go
+@@ -1,3 +1,4 @@
+ package a
+ 
++// TODO: a
+ // TODO: b
+diff --git a/b/b.go b/b/b.go
+deleted file mode 100644
+index 5bcb3bc..0000000
+--- a/b/b.go
++++ /dev/null
+@@ -1,1 +0,0 @@
+-package b
+`
+
+func TestDiffChangedFiles(t *testing.T) {
+	p := &Diff{patch: testPatch}
+	assert.True(t, p.Enabled())
+
+	files, err := p.ChangedFiles()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a/a.go"}, files)
+}
+
+func TestDiffPatchReadOnce(t *testing.T) {
+	dir, err := ioutil.TempDir("", "golangci-lint-diff-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	patchPath := filepath.Join(dir, "diff.patch")
+	require.NoError(t, ioutil.WriteFile(patchPath, []byte(testPatch), 0644))
+
+	p := NewDiff(&config.Issues{DiffPatchFilePath: patchPath})
+	files, err := p.ChangedFiles()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a/a.go"}, files)
+
+	// Issues are processed by the patch read for the packages filtering.
+	require.NoError(t, os.Remove(patchPath))
+	issues := process(t, p, newDiffIssue("a/a.go", 3), newDiffIssue("a/a.go", 4))
+	require.Len(t, issues, 1)
+	assert.Equal(t, 3, issues[0].Line())
+}
+
+func newDiffIssue(path string, line int) result.Issue {
+	return result.Issue{
+		FromLinter: "linter",
+		Pos:        token.Position{Filename: path, Line: line},
+	}
+}
+
+func TestDiffGranularity(t *testing.T) {
+	issues := []result.Issue{
+		newDiffIssue("a/a.go", 1),
+		newDiffIssue("a/a.go", 3),
+		newDiffIssue("a/a.go", 5),
+	}
+
+	lines := process(t, &Diff{patch: testPatch, granularity: config.DiffGranularityLine}, issues...)
+	assert.Len(t, lines, 1)
+	assert.Equal(t, 3, lines[0].Line())
+
+	hunks := process(t, &Diff{patch: testPatch, granularity: config.DiffGranularityHunk}, issues...)
+	assert.Len(t, hunks, 2)
+	assert.Equal(t, 1, hunks[0].Line())
+	assert.Equal(t, 3, hunks[1].Line())
+}
+
+func TestDiffExplainFiltered(t *testing.T) {
+	patch := testPatch + `diff --git a/c/c.go b/c/c.go
+index 8fd8b0a..c0e8f6e 100644
+--- a/c/c.go
++++ b/c/c.go
+@@ -2,1 +2,1 @@ func c() {
+-	a()
++	b()
+@@ -20,2 +20,3 @@
+ 	c()
++	d()
+ }
+`
+	issues := []result.Issue{
+		newDiffIssue("c/c.go", 10),
+		newDiffIssue("c/c.go", 15),
+		newDiffIssue("d/d.go", 1),
+	}
+
+	p := &Diff{patch: patch, granularity: config.DiffGranularityLine}
+	assert.Empty(t, process(t, p, issues...))
+	assert.Equal(t, "line isn't changed in the diff, nearest hunk is @@ -2,1 +2,1 @@", p.ExplainFiltered(&issues[0]))
+	assert.Equal(t, "line isn't changed in the diff, nearest hunk is @@ -20,2 +20,3 @@", p.ExplainFiltered(&issues[1]))
+	assert.Equal(t, "file isn't changed in the diff", p.ExplainFiltered(&issues[2]))
+
+	p = &Diff{patch: patch, fromRev: "HEAD~1", granularity: config.DiffGranularityHunk}
+	p.changes, _ = parsePatch([]byte(patch))
+	assert.Equal(t, "line isn't in a changed hunk since revision HEAD~1, nearest hunk is @@ -2,1 +2,1 @@",
+		p.ExplainFiltered(&issues[0]))
+}
+
+func TestDiffFunctionGranularity(t *testing.T) {
+	file := filepath.Join("testdata", "diff_function.go")
+	patch := `--- a/` + file + `
++++ b/` + file + `
+@@ -3,3 +3,4 @@
+ func changed() {
++	a := 1
+-	a := 2
+ 	_ = a
+`
+
+	issues := []result.Issue{
+		newDiffIssue(file, 3),
+		newDiffIssue(file, 8),
+	}
+
+	p := &Diff{patch: patch, granularity: config.DiffGranularityFunction}
+	processedIssues := process(t, p, issues...)
+	assert.Len(t, processedIssues, 1)
+	assert.Equal(t, 3, processedIssues[0].Line())
+	assert.Equal(t, "line isn't changed and isn't in a changed function in the diff, nearest hunk is @@ -3,3 +3,4 @@",
+		p.ExplainFiltered(&issues[1]))
+}
+
+func TestDiffFromMergeBase(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git isn't installed")
+	}
+
+	repo, err := ioutil.TempDir("", "golangci-lint-diff-")
+	require.NoError(t, err)
+	defer os.RemoveAll(repo)
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(repo))
+	defer func() {
+		require.NoError(t, os.Chdir(wd))
+	}()
+
+	git := func(args ...string) {
+		args = append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)
+		out, err := exec.Command("git", args...).CombinedOutput()
+		require.NoError(t, err, "git %s: %s", strings.Join(args, " "), out)
+	}
+	commitFile := func(lines ...string) {
+		require.NoError(t, ioutil.WriteFile("a.go", []byte(strings.Join(lines, "\n")+"\n"), 0644))
+		git("add", "a.go")
+		git("commit", "-q", "-m", "change")
+	}
+
+	git("init", "-q")
+	git("checkout", "-q", "-b", "main")
+	commitFile("package a", "", "var b = 1", "", "var c = 1")
+
+	// The branch changes line 3, main changes line 5 after the branch point.
+	git("checkout", "-q", "-b", "feature")
+	commitFile("package a", "", "var b = 2", "", "var c = 1")
+	git("checkout", "-q", "main")
+	commitFile("package a", "", "var b = 1", "", "var c = 2")
+	git("checkout", "-q", "feature")
+
+	issues := []result.Issue{
+		newDiffIssue("a.go", 3),
+		newDiffIssue("a.go", 5),
+	}
+
+	// Changes of main after the branch point aren't the branch changes.
+	p := NewDiff(&config.Issues{DiffFromMergeBase: "main"})
+	processedIssues := process(t, p, issues...)
+	require.Len(t, processedIssues, 1)
+	assert.Equal(t, 3, processedIssues[0].Line())
+	assert.Equal(t, "line isn't changed since the merge base with main, nearest hunk is @@ -1,5 +1,5 @@",
+		p.ExplainFiltered(&issues[1]))
+
+	// The diff with main itself includes them.
+	assert.Len(t, process(t, NewDiff(&config.Issues{DiffFromRevision: "main"}), issues...), 2)
+}
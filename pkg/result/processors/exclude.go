@@ -1,6 +1,7 @@
 package processors
 
 import (
+	"fmt"
 	"regexp"
 
 	"github.com/golangci/golangci-lint/pkg/result"
@@ -36,6 +37,10 @@ func (p Exclude) Process(issues []result.Issue) ([]result.Issue, error) {
 	}), nil
 }
 
+func (p Exclude) ExplainFiltered(i *result.Issue) string {
+	return fmt.Sprintf("text %q matches exclude patterns", p.pattern.FindString(i.Text))
+}
+
 func (p Exclude) Finish() {}
 
 type ExcludeCaseSensitive struct {
@@ -1,6 +1,7 @@
 package processors
 
 import (
+	"fmt"
 	"regexp"
 
 	"github.com/golangci/golangci-lint/pkg/fsutils"
@@ -66,6 +67,16 @@ func (p ExcludeRules) Process(issues []result.Issue) ([]result.Issue, error) {
 	}), nil
 }
 
+func (p ExcludeRules) ExplainFiltered(i *result.Issue) string {
+	for ind, rule := range p.rules {
+		rule := rule
+		if rule.match(i, p.lineCache, p.log) {
+			return fmt.Sprintf("matches exclude rule #%d", ind)
+		}
+	}
+	return ""
+}
+
 func (ExcludeRules) Name() string { return "exclude-rules" }
 func (ExcludeRules) Finish()      {}
 
@@ -47,3 +47,9 @@ func TestExcludeCaseSensitive(t *testing.T) {
 	}
 	assert.Equal(t, texts[:len(texts)-1], processedTexts)
 }
+
+func TestExcludeExplainFiltered(t *testing.T) {
+	p := NewExclude("^exclude")
+	issue := newIssueFromTextTestCase("exclude me")
+	assert.Equal(t, `text "exclude" matches exclude patterns`, p.ExplainFiltered(&issue))
+}
@@ -0,0 +1,45 @@
+package processors
+
+import (
+	"github.com/golangci/golangci-lint/pkg/config"
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+// FailThreshold records whether any issue is at least as severe as the fail threshold.
+// It must go before limits: an issue hidden by a limit must fail the run too.
+// It doesn't filter issues.
+type FailThreshold struct {
+	threshold string
+	needFix   bool
+	reached   bool
+}
+
+var _ Processor = &FailThreshold{}
+
+func NewFailThreshold(cfg *config.Config) *FailThreshold {
+	return &FailThreshold{
+		threshold: cfg.Severity.FailThreshold,
+		needFix:   cfg.Issues.NeedFix,
+	}
+}
+
+func (p *FailThreshold) Process(issues []result.Issue) ([]result.Issue, error) {
+	for i := range issues {
+		if p.needFix && issues[i].Replacement != nil {
+			continue // fixed issues don't fail the run, failed fixes are checked by the command
+		}
+		if issues[i].IsSeverityAtLeast(p.threshold) {
+			p.reached = true
+			break
+		}
+	}
+	return issues, nil
+}
+
+// Reached reports whether any processed issue fails the run.
+func (p FailThreshold) Reached() bool {
+	return p.reached
+}
+
+func (FailThreshold) Name() string { return "fail_threshold" }
+func (FailThreshold) Finish()      {}
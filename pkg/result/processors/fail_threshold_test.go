@@ -0,0 +1,63 @@
+package processors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/golangci/golangci-lint/pkg/config"
+	"github.com/golangci/golangci-lint/pkg/logutils"
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func newSeverityIssue(severity string) result.Issue {
+	i := newFromLinterIssue("gosec")
+	i.Severity = severity
+	return i
+}
+
+func TestFailThresholdIssueBeyondLimit(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Severity.FailThreshold = result.SeverityError
+
+	threshold := NewFailThreshold(cfg)
+	limit := NewMaxFromLinter(2, logutils.NewStderrLog(""), cfg)
+
+	// The only error is the third issue of the linter: the limit hides it.
+	issues := []result.Issue{
+		newSeverityIssue(result.SeverityWarning),
+		newSeverityIssue(result.SeverityWarning),
+		newSeverityIssue(result.SeverityError),
+	}
+	issues = process(t, threshold, issues...)
+	issues = process(t, limit, issues...)
+
+	assert.Len(t, issues, 2)
+	for _, i := range issues {
+		assert.Equal(t, result.SeverityWarning, i.Severity)
+	}
+	assert.True(t, threshold.Reached())
+}
+
+func TestFailThresholdNotReached(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Severity.FailThreshold = result.SeverityError
+
+	p := NewFailThreshold(cfg)
+	processAssertSame(t, p, newSeverityIssue(result.SeverityWarning))
+	assert.False(t, p.Reached())
+}
+
+func TestFailThresholdFixedIssues(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Issues.NeedFix = true
+
+	p := NewFailThreshold(cfg)
+	fixable := newSeverityIssue(result.SeverityError)
+	fixable.Replacement = &result.Replacement{NeedOnlyDelete: true}
+	processAssertSame(t, p, fixable)
+	assert.False(t, p.Reached())
+
+	processAssertSame(t, p, newSeverityIssue(""))
+	assert.True(t, p.Reached())
+}
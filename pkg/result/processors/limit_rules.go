@@ -0,0 +1,72 @@
+package processors
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/golangci/golangci-lint/pkg/config"
+	"github.com/golangci/golangci-lint/pkg/logutils"
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+// noLimitRule is the index of the group of issues not matched by any limit rule:
+// global limits are applied to them.
+const noLimitRule = -1
+
+type limitRule struct {
+	baseRule
+	maxIssuesPerLinter *int
+	maxSameIssues      *int
+	maxIssuesPerFile   *int
+}
+
+type limitRules []limitRule
+
+func newLimitRules(rules []config.LimitRule) limitRules {
+	parsedRules := make(limitRules, 0, len(rules))
+	for _, rule := range rules {
+		parsedRule := limitRule{
+			maxIssuesPerLinter: rule.MaxIssuesPerLinter,
+			maxSameIssues:      rule.MaxSameIssues,
+			maxIssuesPerFile:   rule.MaxIssuesPerFile,
+		}
+		parsedRule.linters = rule.Linters
+		if rule.Path != "" {
+			parsedRule.path = regexp.MustCompile(normalizePathInRegex(rule.Path))
+		}
+		parsedRules = append(parsedRules, parsedRule)
+	}
+	return parsedRules
+}
+
+// find returns the index and the limit of the first rule matching the issue
+// and having the limit selected by getLimit set.
+func (rs limitRules) find(i *result.Issue, getLimit func(r *limitRule) *int) (int, int, bool) {
+	for ind := range rs {
+		r := &rs[ind]
+		limit := getLimit(r)
+		if limit != nil && r.match(i, nil, nil) {
+			return ind, *limit, true
+		}
+	}
+
+	return noLimitRule, 0, false
+}
+
+func limitRuleDescription(ruleIndex int, option string) string {
+	if ruleIndex == noLimitRule {
+		return fmt.Sprintf("use --%s", option)
+	}
+
+	return fmt.Sprintf("limited by %s of issues.limits rule #%d", option, ruleIndex)
+}
+
+// logHiddenIssues logs issues hidden by a limit: hiding by the global limits is expected
+// and logged at the info level, hiding by a configured limit rule is a warning.
+func logHiddenIssues(log logutils.Log, ruleIndex int, format string, args ...interface{}) {
+	if ruleIndex == noLimitRule {
+		log.Infof(format, args...)
+		return
+	}
+	log.Warnf(format, args...)
+}
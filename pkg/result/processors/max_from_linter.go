@@ -1,26 +1,33 @@
 package processors
 
 import (
+	"fmt"
+	"sort"
+
 	"github.com/golangci/golangci-lint/pkg/config"
 	"github.com/golangci/golangci-lint/pkg/logutils"
 	"github.com/golangci/golangci-lint/pkg/result"
 )
 
 type MaxFromLinter struct {
-	lc    linterToCountMap
-	limit int
-	log   logutils.Log
-	cfg   *config.Config
+	lc     map[int]linterToCountMap // limit rule index -> counts
+	limits map[int]int
+	limit  int
+	rules  limitRules
+	log    logutils.Log
+	cfg    *config.Config
 }
 
 var _ Processor = &MaxFromLinter{}
 
 func NewMaxFromLinter(limit int, log logutils.Log, cfg *config.Config) *MaxFromLinter {
 	return &MaxFromLinter{
-		lc:    linterToCountMap{},
-		limit: limit,
-		log:   log,
-		cfg:   cfg,
+		lc:     map[int]linterToCountMap{},
+		limits: map[int]int{noLimitRule: limit},
+		limit:  limit,
+		rules:  newLimitRules(cfg.Issues.LimitRules),
+		log:    log,
+		cfg:    cfg,
 	}
 }
 
@@ -28,8 +35,12 @@ func (p MaxFromLinter) Name() string {
 	return "max_from_linter"
 }
 
+func getMaxIssuesPerLinter(r *limitRule) *int {
+	return r.maxIssuesPerLinter
+}
+
 func (p *MaxFromLinter) Process(issues []result.Issue) ([]result.Issue, error) {
-	if p.limit <= 0 { // no limit
+	if p.limit <= 0 && len(p.rules) == 0 { // no limit
 		return issues, nil
 	}
 
@@ -39,16 +50,50 @@ func (p *MaxFromLinter) Process(issues []result.Issue) ([]result.Issue, error) {
 			return true
 		}
 
-		p.lc[i.FromLinter]++ // always inc for stat
-		return p.lc[i.FromLinter] <= p.limit
+		ruleIndex, limit, ok := p.rules.find(i, getMaxIssuesPerLinter)
+		if !ok {
+			limit = p.limit
+		}
+		if limit <= 0 {
+			return true
+		}
+		p.limits[ruleIndex] = limit
+
+		lc := p.lc[ruleIndex]
+		if lc == nil {
+			lc = linterToCountMap{}
+			p.lc[ruleIndex] = lc
+		}
+
+		lc[i.FromLinter]++ // always inc for stat
+		return lc[i.FromLinter] <= limit
 	}), nil
 }
 
+func (p MaxFromLinter) ExplainFiltered(i *result.Issue) string {
+	ruleIndex, limit, ok := p.rules.find(i, getMaxIssuesPerLinter)
+	if !ok {
+		limit = p.limit
+	}
+
+	return fmt.Sprintf("more than %d issues from linter %s, %s",
+		limit, i.FromLinter, limitRuleDescription(ruleIndex, "max-issues-per-linter"))
+}
+
 func (p MaxFromLinter) Finish() {
-	walkStringToIntMapSortedByValue(p.lc, func(linter string, count int) {
-		if count > p.limit {
-			p.log.Infof("%d/%d issues from linter %s were hidden, use --max-issues-per-linter",
-				count-p.limit, count, linter)
-		}
-	})
+	ruleIndexes := make([]int, 0, len(p.lc))
+	for ruleIndex := range p.lc {
+		ruleIndexes = append(ruleIndexes, ruleIndex)
+	}
+	sort.Ints(ruleIndexes)
+
+	for _, ruleIndex := range ruleIndexes {
+		limit := p.limits[ruleIndex]
+		walkStringToIntMapSortedByValue(p.lc[ruleIndex], func(linter string, count int) {
+			if count > limit {
+				logHiddenIssues(p.log, ruleIndex, "%d/%d issues from linter %s were hidden, %s",
+					count-limit, count, linter, limitRuleDescription(ruleIndex, "max-issues-per-linter"))
+			}
+		})
+	}
 }
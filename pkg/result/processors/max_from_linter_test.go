@@ -16,3 +16,29 @@ func TestMaxFromLinter(t *testing.T) {
 	processAssertSame(t, p, gofmt)     // ok: another
 	processAssertEmpty(t, p, gosimple) // skip
 }
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestMaxFromLinterLimitRules(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Issues.LimitRules = []config.LimitRule{
+		{Linters: []string{"gosec"}, MaxIssuesPerLinter: intPtr(0)},
+		{Path: "^cmd/", MaxIssuesPerLinter: intPtr(2)},
+	}
+	p := NewMaxFromLinter(1, logutils.NewStderrLog(""), cfg)
+
+	gosec := newFromLinterIssue("gosec")
+	processAssertSame(t, p, gosec)
+	processAssertSame(t, p, gosec) // unlimited by the rule
+
+	cmdIssue := newIssueFromIssueTestCase(issueTestCase{Path: "cmd/main.go", Linter: "gosimple"})
+	processAssertSame(t, p, cmdIssue)
+	processAssertSame(t, p, cmdIssue)
+	processAssertEmpty(t, p, cmdIssue)
+
+	gosimple := newFromLinterIssue("gosimple")
+	processAssertSame(t, p, gosimple)  // counted separately from cmd/ issues
+	processAssertEmpty(t, p, gosimple) // global limit
+}
@@ -1,21 +1,34 @@
 package processors
 
 import (
+	"fmt"
+	"sort"
+
 	"github.com/golangci/golangci-lint/pkg/config"
+	"github.com/golangci/golangci-lint/pkg/logutils"
 	"github.com/golangci/golangci-lint/pkg/result"
 )
 
 type linterToCountMap map[string]int
 type fileToLinterToCountMap map[string]linterToCountMap
 
+type perFileLimit struct {
+	ruleIndex int
+	linter    string
+	limit     int
+}
+
 type MaxPerFileFromLinter struct {
 	flc                        fileToLinterToCountMap
 	maxPerFileFromLinterConfig map[string]int
+	rules                      limitRules
+	hidden                     map[perFileLimit]int
+	log                        logutils.Log
 }
 
 var _ Processor = &MaxPerFileFromLinter{}
 
-func NewMaxPerFileFromLinter(cfg *config.Config) *MaxPerFileFromLinter {
+func NewMaxPerFileFromLinter(cfg *config.Config, log logutils.Log) *MaxPerFileFromLinter {
 	maxPerFileFromLinterConfig := map[string]int{
 		"typecheck": 3,
 	}
@@ -29,6 +42,9 @@ func NewMaxPerFileFromLinter(cfg *config.Config) *MaxPerFileFromLinter {
 	return &MaxPerFileFromLinter{
 		flc:                        fileToLinterToCountMap{},
 		maxPerFileFromLinterConfig: maxPerFileFromLinterConfig,
+		rules:                      newLimitRules(cfg.Issues.LimitRules),
+		hidden:                     map[perFileLimit]int{},
+		log:                        log,
 	}
 }
 
@@ -36,10 +52,17 @@ func (p MaxPerFileFromLinter) Name() string {
 	return "max_per_file_from_linter"
 }
 
+func getMaxIssuesPerFile(r *limitRule) *int {
+	return r.maxIssuesPerFile
+}
+
 func (p *MaxPerFileFromLinter) Process(issues []result.Issue) ([]result.Issue, error) {
 	return filterIssues(issues, func(i *result.Issue) bool {
-		limit := p.maxPerFileFromLinterConfig[i.FromLinter]
-		if limit == 0 {
+		ruleIndex, limit, ok := p.rules.find(i, getMaxIssuesPerFile)
+		if !ok {
+			limit = p.maxPerFileFromLinterConfig[i.FromLinter]
+		}
+		if limit <= 0 {
 			return true
 		}
 
@@ -49,6 +72,7 @@ func (p *MaxPerFileFromLinter) Process(issues []result.Issue) ([]result.Issue, e
 		}
 		count := p.flc[i.FilePath()][i.FromLinter]
 		if count >= limit {
+			p.hidden[perFileLimit{ruleIndex: ruleIndex, linter: i.FromLinter, limit: limit}]++
 			return false
 		}
 
@@ -57,4 +81,37 @@ func (p *MaxPerFileFromLinter) Process(issues []result.Issue) ([]result.Issue, e
 	}), nil
 }
 
-func (p MaxPerFileFromLinter) Finish() {}
+func (p MaxPerFileFromLinter) ExplainFiltered(i *result.Issue) string {
+	ruleIndex, limit, ok := p.rules.find(i, getMaxIssuesPerFile)
+	if !ok {
+		return fmt.Sprintf("more than %d issues from linter %s in the file, it's the default limit",
+			p.maxPerFileFromLinterConfig[i.FromLinter], i.FromLinter)
+	}
+
+	return fmt.Sprintf("more than %d issues from linter %s in the file, %s",
+		limit, i.FromLinter, limitRuleDescription(ruleIndex, "max-issues-per-file"))
+}
+
+func (p MaxPerFileFromLinter) Finish() {
+	limits := make([]perFileLimit, 0, len(p.hidden))
+	for l := range p.hidden {
+		limits = append(limits, l)
+	}
+	sort.Slice(limits, func(i, j int) bool {
+		if limits[i].ruleIndex != limits[j].ruleIndex {
+			return limits[i].ruleIndex < limits[j].ruleIndex
+		}
+		return limits[i].linter < limits[j].linter
+	})
+
+	for _, l := range limits {
+		if l.ruleIndex == noLimitRule {
+			p.log.Infof("%d issues from linter %s were hidden by the default limit of %d issues per file",
+				p.hidden[l], l.linter, l.limit)
+			continue
+		}
+
+		p.log.Warnf("%d issues from linter %s were hidden, %s",
+			p.hidden[l], l.linter, limitRuleDescription(l.ruleIndex, "max-issues-per-file"))
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/golangci/golangci-lint/pkg/config"
+	"github.com/golangci/golangci-lint/pkg/logutils"
 	"github.com/golangci/golangci-lint/pkg/result"
 )
 
@@ -14,14 +15,14 @@ func newFromLinterIssue(linterName string) result.Issue {
 }
 
 func TestMaxPerFileFromLinterUnlimited(t *testing.T) {
-	p := NewMaxPerFileFromLinter(&config.Config{})
+	p := NewMaxPerFileFromLinter(&config.Config{}, logutils.NewStderrLog(""))
 	gosimple := newFromLinterIssue("gosimple")
 	processAssertSame(t, p, gosimple) // collect stat
 	processAssertSame(t, p, gosimple) // check not limits
 }
 
 func TestMaxPerFileFromLinter(t *testing.T) {
-	p := NewMaxPerFileFromLinter(&config.Config{})
+	p := NewMaxPerFileFromLinter(&config.Config{}, logutils.NewStderrLog(""))
 	for _, name := range []string{"gofmt", "goimports"} {
 		limited := newFromLinterIssue(name)
 		gosimple := newFromLinterIssue("gosimple")
@@ -30,3 +31,21 @@ func TestMaxPerFileFromLinter(t *testing.T) {
 		processAssertEmpty(t, p, limited)
 	}
 }
+
+func TestMaxPerFileFromLinterLimitRules(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Issues.LimitRules = []config.LimitRule{
+		{Linters: []string{"gofmt"}, MaxIssuesPerFile: intPtr(0)},
+		{Linters: []string{"lll"}, MaxIssuesPerFile: intPtr(2)},
+	}
+	p := NewMaxPerFileFromLinter(cfg, logutils.NewStderrLog(""))
+
+	gofmt := newFromLinterIssue("gofmt")
+	processAssertSame(t, p, gofmt)
+	processAssertSame(t, p, gofmt) // unlimited by the rule
+
+	lll := newFromLinterIssue("lll")
+	processAssertSame(t, p, lll)
+	processAssertSame(t, p, lll)
+	processAssertEmpty(t, p, lll)
+}
@@ -1,6 +1,7 @@
 package processors
 
 import (
+	"fmt"
 	"sort"
 
 	"github.com/golangci/golangci-lint/pkg/config"
@@ -11,20 +12,24 @@ import (
 type textToCountMap map[string]int
 
 type MaxSameIssues struct {
-	tc    textToCountMap
-	limit int
-	log   logutils.Log
-	cfg   *config.Config
+	tc     map[int]textToCountMap // limit rule index -> counts
+	limits map[int]int
+	limit  int
+	rules  limitRules
+	log    logutils.Log
+	cfg    *config.Config
 }
 
 var _ Processor = &MaxSameIssues{}
 
 func NewMaxSameIssues(limit int, log logutils.Log, cfg *config.Config) *MaxSameIssues {
 	return &MaxSameIssues{
-		tc:    textToCountMap{},
-		limit: limit,
-		log:   log,
-		cfg:   cfg,
+		tc:     map[int]textToCountMap{},
+		limits: map[int]int{noLimitRule: limit},
+		limit:  limit,
+		rules:  newLimitRules(cfg.Issues.LimitRules),
+		log:    log,
+		cfg:    cfg,
 	}
 }
 
@@ -32,8 +37,12 @@ func (MaxSameIssues) Name() string {
 	return "max_same_issues"
 }
 
+func getMaxSameIssues(r *limitRule) *int {
+	return r.maxSameIssues
+}
+
 func (p *MaxSameIssues) Process(issues []result.Issue) ([]result.Issue, error) {
-	if p.limit <= 0 { // no limit
+	if p.limit <= 0 && len(p.rules) == 0 { // no limit
 		return issues, nil
 	}
 
@@ -43,18 +52,52 @@ func (p *MaxSameIssues) Process(issues []result.Issue) ([]result.Issue, error) {
 			return true
 		}
 
-		p.tc[i.Text]++ // always inc for stat
-		return p.tc[i.Text] <= p.limit
+		ruleIndex, limit, ok := p.rules.find(i, getMaxSameIssues)
+		if !ok {
+			limit = p.limit
+		}
+		if limit <= 0 {
+			return true
+		}
+		p.limits[ruleIndex] = limit
+
+		tc := p.tc[ruleIndex]
+		if tc == nil {
+			tc = textToCountMap{}
+			p.tc[ruleIndex] = tc
+		}
+
+		tc[i.Text]++ // always inc for stat
+		return tc[i.Text] <= limit
 	}), nil
 }
 
+func (p MaxSameIssues) ExplainFiltered(i *result.Issue) string {
+	ruleIndex, limit, ok := p.rules.find(i, getMaxSameIssues)
+	if !ok {
+		limit = p.limit
+	}
+
+	return fmt.Sprintf("more than %d issues with the same text, %s",
+		limit, limitRuleDescription(ruleIndex, "max-same-issues"))
+}
+
 func (p MaxSameIssues) Finish() {
-	walkStringToIntMapSortedByValue(p.tc, func(text string, count int) {
-		if count > p.limit {
-			p.log.Infof("%d/%d issues with text %q were hidden, use --max-same-issues",
-				count-p.limit, count, text)
-		}
-	})
+	ruleIndexes := make([]int, 0, len(p.tc))
+	for ruleIndex := range p.tc {
+		ruleIndexes = append(ruleIndexes, ruleIndex)
+	}
+	sort.Ints(ruleIndexes)
+
+	for _, ruleIndex := range ruleIndexes {
+		limit := p.limits[ruleIndex]
+		walkStringToIntMapSortedByValue(p.tc[ruleIndex], func(text string, count int) {
+			if count > limit {
+				logHiddenIssues(p.log, ruleIndex, "%d/%d issues with text %q were hidden, %s",
+					count-limit, count, text, limitRuleDescription(ruleIndex, "max-same-issues"))
+			}
+		})
+	}
 }
 
 type kv struct {
@@ -21,3 +21,42 @@ func TestMaxSameIssues(t *testing.T) {
 	processAssertSame(t, p, i2)  // ok: another
 	processAssertEmpty(t, p, i1) // skip
 }
+
+func TestMaxSameIssuesLimitRules(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Issues.LimitRules = []config.LimitRule{
+		{Linters: []string{"gosec"}, MaxSameIssues: intPtr(0)},
+	}
+	p := NewMaxSameIssues(1, logutils.NewStderrLog(""), cfg)
+
+	gosec := result.Issue{Text: "1", FromLinter: "gosec"}
+	processAssertSame(t, p, gosec)
+	processAssertSame(t, p, gosec) // unlimited by the rule
+
+	other := result.Issue{Text: "1", FromLinter: "gosimple"}
+	processAssertSame(t, p, other)
+	processAssertEmpty(t, p, other)
+}
+
+func TestMaxSameIssuesFinishLogLevel(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Issues.LimitRules = []config.LimitRule{
+		{Linters: []string{"gosec"}, MaxSameIssues: intPtr(1)},
+	}
+
+	log := logutils.NewMockLog()
+	// Hiding by the global limit is expected: not a warning.
+	log.On("Infof", "%d/%d issues with text %q were hidden, %s",
+		1, 2, "1", "use --max-same-issues").Once()
+	// Hiding by a configured rule is.
+	log.On("Warnf", "%d/%d issues with text %q were hidden, %s",
+		1, 2, "2", "limited by max-same-issues of issues.limits rule #0").Once()
+
+	p := NewMaxSameIssues(1, log, cfg)
+	other := result.Issue{Text: "1", FromLinter: "gosimple"}
+	gosec := result.Issue{Text: "2", FromLinter: "gosec"}
+	process(t, p, other, other, gosec, gosec)
+	p.Finish()
+
+	log.AssertExpectations(t)
+}
@@ -0,0 +1,287 @@
+package processors
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/golangci/golangci-lint/pkg/config"
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+// duplicateRule matches issues of one linter that describe the same defect
+// as the other rules of its group. Empty text matches any issue of the linter.
+// The ident regexp extracts the identifier of the defect from the text by its first group.
+type duplicateRule struct {
+	linter string
+	text   *regexp.Regexp
+	ident  *regexp.Regexp
+}
+
+func (r duplicateRule) match(i *result.Issue) bool {
+	if i.FromLinter != r.linter {
+		return false
+	}
+
+	return r.text == nil || r.text.MatchString(i.Text)
+}
+
+// identifier returns the identifier of the defect or "" if the text doesn't have it.
+func (r duplicateRule) identifier(i *result.Issue) string {
+	if r.ident == nil {
+		return ""
+	}
+
+	m := r.ident.FindStringSubmatch(i.Text)
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
+
+func newDuplicateRule(linter, text, ident string) duplicateRule {
+	r := duplicateRule{linter: linter}
+	if text != "" {
+		r.text = regexp.MustCompile(text)
+	}
+	if ident != "" {
+		r.ident = regexp.MustCompile(ident)
+	}
+	return r
+}
+
+const backquotedIdent = "`([^`]+)`"
+
+// duplicateGroups is the equivalence table of linter rules reporting the same defect.
+// Rules inside a group are sorted by default preference: the first matched rule wins.
+// If all rules of a group extract identifiers, only issues about the same identifier are merged,
+// else issues of the group on the same line are merged.
+var duplicateGroups = [][]duplicateRule{
+	{
+		newDuplicateRule("errcheck", "", ""),
+		newDuplicateRule("gosec", "^G104:", ""),
+	},
+	{
+		newDuplicateRule("unused", "is unused$", backquotedIdent),
+		newDuplicateRule("deadcode", "is unused$", backquotedIdent),
+		newDuplicateRule("varcheck", "is unused$", backquotedIdent),
+		newDuplicateRule("structcheck", "is unused$", backquotedIdent),
+	},
+	{
+		newDuplicateRule("ineffassign", "^ineffectual assignment to", "^ineffectual assignment to `?([^` ]+)`?"),
+		newDuplicateRule("staticcheck", "^SA4006:", "this value of `?([^` ]+)`? is never used"),
+	},
+	{
+		newDuplicateRule("stylecheck", "^ST1005:", ""),
+		newDuplicateRule("golint", "^error strings should not be capitalized", ""),
+	},
+	{
+		// ALL_CAPS messages don't have identifiers, they are merged by lines.
+		newDuplicateRule("stylecheck", "^ST1003:", `(\S+) should be \S+$`),
+		newDuplicateRule("golint", "(should be|should not use (underscores|ALL_CAPS))", `(\S+) should be \S+$`),
+	},
+	{
+		newDuplicateRule("stylecheck", "^ST1006:", ""),
+		newDuplicateRule("golint", "^receiver name should (not be an underscore|be a reflection of its identity)", ""),
+	},
+	{
+		newDuplicateRule("stylecheck", "^ST1016:", ""),
+		newDuplicateRule("golint", "^receiver name .* should be consistent with previous receiver name", ""),
+	},
+	{
+		newDuplicateRule("stylecheck", "^ST1012:", `error var (\S+) should have name`),
+		newDuplicateRule("golint", "^error var .* should have name of the form", `error var (\S+) should have name`),
+	},
+	{
+		newDuplicateRule("gosimple", "^S1005:", ""),
+		newDuplicateRule("golint", "^should omit (2nd value|values) from range", ""),
+	},
+	{
+		newDuplicateRule("goimports", "", ""),
+		newDuplicateRule("gofmt", "", ""),
+	},
+}
+
+type duplicateKey struct {
+	file  string
+	line  int
+	group int
+	ident string
+}
+
+// MergeDuplicates merges issues reported on the same line by different linters
+// for the same defect of the same identifier (see duplicateGroups): issues of the preferred linter are kept,
+// issues of other linters are dropped. Issues of one linter are never merged.
+type MergeDuplicates struct {
+	cfg *config.Config
+
+	// linter name -> index in the list of preferred linters
+	preferred map[string]int
+
+	winners map[duplicateKey]string
+}
+
+var _ Processor = &MergeDuplicates{}
+
+func NewMergeDuplicates(cfg *config.Config) *MergeDuplicates {
+	preferred := map[string]int{}
+	for i, name := range cfg.Issues.MergeDuplicatesPrefer {
+		preferred[name] = i
+	}
+
+	return &MergeDuplicates{
+		cfg:       cfg,
+		preferred: preferred,
+		winners:   map[duplicateKey]string{},
+	}
+}
+
+func (p MergeDuplicates) Name() string {
+	return "merge_duplicates"
+}
+
+// matchDuplicateGroup returns the key of the issue in its group of duplicates
+// and the rank of its rule in the group.
+func matchDuplicateGroup(i *result.Issue) (key duplicateKey, rank int, ok bool) {
+	for groupIdx, rules := range duplicateGroups {
+		for ruleIdx, rule := range rules {
+			if !rule.match(i) {
+				continue
+			}
+
+			key = duplicateKey{file: i.FilePath(), line: i.Line(), group: groupIdx}
+			if groupHasIdents(rules) {
+				key.ident = rule.identifier(i)
+			}
+			return key, ruleIdx, true
+		}
+	}
+
+	return duplicateKey{}, 0, false
+}
+
+func groupHasIdents(rules []duplicateRule) bool {
+	for _, rule := range rules {
+		if rule.ident == nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (p *MergeDuplicates) Process(issues []result.Issue) ([]result.Issue, error) {
+	if !p.cfg.Issues.MergeDuplicates {
+		return issues, nil
+	}
+
+	type candidate struct {
+		index int
+		rank  int
+	}
+	groups := map[duplicateKey][]candidate{}
+	var keys []duplicateKey
+
+	for ind := range issues {
+		i := &issues[ind]
+		if i.Replacement != nil && p.cfg.Issues.NeedFix {
+			// every fixable issue must reach the fixer
+			continue
+		}
+
+		key, rank, ok := matchDuplicateGroup(i)
+		if !ok {
+			continue
+		}
+
+		if _, seen := groups[key]; !seen {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], candidate{index: ind, rank: p.rank(i.FromLinter, rank)})
+	}
+
+	dropped := map[int]bool{}
+	for _, key := range keys {
+		candidates := groups[key]
+		if len(candidates) < 2 {
+			continue
+		}
+
+		sort.SliceStable(candidates, func(a, b int) bool {
+			return candidates[a].rank < candidates[b].rank
+		})
+
+		// All issues of the winning linter are kept: one linter can report
+		// several distinct issues on the same line (e.g. `var a, b int`).
+		winnerLinter := issues[candidates[0].index].FromLinter
+		p.winners[key] = winnerLinter
+
+		var alsoReportedBy []string
+		var winners []*result.Issue
+		for _, c := range candidates {
+			linterName := issues[c.index].FromLinter
+			if linterName == winnerLinter {
+				winners = append(winners, &issues[c.index])
+				continue
+			}
+
+			if !containsString(alsoReportedBy, linterName) {
+				alsoReportedBy = append(alsoReportedBy, linterName)
+			}
+			dropped[c.index] = true
+		}
+
+		for _, w := range winners {
+			for _, linterName := range alsoReportedBy {
+				if !containsString(w.AlsoReportedBy, linterName) {
+					w.AlsoReportedBy = append(w.AlsoReportedBy, linterName)
+				}
+			}
+		}
+	}
+
+	if len(dropped) == 0 {
+		return issues, nil
+	}
+
+	retIssues := make([]result.Issue, 0, len(issues)-len(dropped))
+	for ind := range issues {
+		if !dropped[ind] {
+			retIssues = append(retIssues, issues[ind])
+		}
+	}
+
+	return retIssues, nil
+}
+
+// rank orders issues of one group: linters from the user's preference list come first,
+// then the rest in the order of the equivalence table.
+func (p MergeDuplicates) rank(linterName string, tableRank int) int {
+	if i, ok := p.preferred[linterName]; ok {
+		return i - len(p.preferred)
+	}
+
+	return tableRank
+}
+
+func (p MergeDuplicates) ExplainFiltered(i *result.Issue) string {
+	key, _, ok := matchDuplicateGroup(i)
+	if !ok {
+		return ""
+	}
+
+	winner := p.winners[key]
+	return fmt.Sprintf("merged into the same issue from %s", winner)
+}
+
+func (p MergeDuplicates) Finish() {}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,117 @@
+package processors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/golangci/golangci-lint/pkg/config"
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func newMergeDuplicatesConfig(prefer ...string) *config.Config {
+	cfg := &config.Config{}
+	cfg.Issues.MergeDuplicates = true
+	cfg.Issues.MergeDuplicatesPrefer = prefer
+	return cfg
+}
+
+func TestMergeDuplicates(t *testing.T) {
+	p := NewMergeDuplicates(newMergeDuplicatesConfig())
+
+	gosec := newIssueFromIssueTestCase(issueTestCase{Path: "a.go", Line: 10, Linter: "gosec", Text: "G104: Errors unhandled."})
+	errcheck := newIssueFromIssueTestCase(issueTestCase{Path: "a.go", Line: 10, Linter: "errcheck", Text: "Error return value is not checked"})
+	otherLine := newIssueFromIssueTestCase(issueTestCase{Path: "a.go", Line: 11, Linter: "gosec", Text: "G104: Errors unhandled."})
+	otherRule := newIssueFromIssueTestCase(issueTestCase{Path: "a.go", Line: 10, Linter: "gosec", Text: "G304: Potential file inclusion"})
+
+	issues := process(t, p, gosec, errcheck, otherLine, otherRule)
+
+	merged := errcheck
+	merged.AlsoReportedBy = []string{"gosec"}
+	assert.Equal(t, []result.Issue{merged, otherLine, otherRule}, issues)
+}
+
+func TestMergeDuplicatesPreferred(t *testing.T) {
+	p := NewMergeDuplicates(newMergeDuplicatesConfig("varcheck"))
+
+	deadcode := newIssueFromIssueTestCase(issueTestCase{Path: "a.go", Line: 3, Linter: "deadcode", Text: "`x` is unused"})
+	unused := newIssueFromIssueTestCase(issueTestCase{Path: "a.go", Line: 3, Linter: "unused", Text: "var `x` is unused"})
+	varcheck := newIssueFromIssueTestCase(issueTestCase{Path: "a.go", Line: 3, Linter: "varcheck", Text: "`x` is unused"})
+
+	issues := process(t, p, deadcode, unused, varcheck)
+
+	merged := varcheck
+	merged.AlsoReportedBy = []string{"unused", "deadcode"}
+	assert.Equal(t, []result.Issue{merged}, issues)
+}
+
+func TestMergeDuplicatesKeepsSameLinterIssues(t *testing.T) {
+	p := NewMergeDuplicates(newMergeDuplicatesConfig())
+
+	unusedA := newIssueFromIssueTestCase(issueTestCase{Path: "a.go", Line: 3, Linter: "unused", Text: "var `a` is unused"})
+	unusedB := newIssueFromIssueTestCase(issueTestCase{Path: "a.go", Line: 3, Linter: "unused", Text: "var `b` is unused"})
+	deadcodeA := newIssueFromIssueTestCase(issueTestCase{Path: "a.go", Line: 3, Linter: "deadcode", Text: "`a` is unused"})
+	deadcodeB := newIssueFromIssueTestCase(issueTestCase{Path: "a.go", Line: 3, Linter: "deadcode", Text: "`b` is unused"})
+
+	issues := process(t, p, unusedA, deadcodeA, unusedB, deadcodeB)
+
+	unusedA.AlsoReportedBy = []string{"deadcode"}
+	unusedB.AlsoReportedBy = []string{"deadcode"}
+	assert.Equal(t, []result.Issue{unusedA, unusedB}, issues)
+}
+
+func TestMergeDuplicatesSingleLinter(t *testing.T) {
+	p := NewMergeDuplicates(newMergeDuplicatesConfig())
+
+	first := newIssueFromIssueTestCase(issueTestCase{Path: "a.go", Line: 10, Linter: "errcheck", Text: "Error return value of `a` is not checked"})
+	second := newIssueFromIssueTestCase(issueTestCase{Path: "a.go", Line: 10, Linter: "errcheck", Text: "Error return value of `b` is not checked"})
+
+	processAssertSame(t, p, first, second)
+}
+
+func TestMergeDuplicatesDisabled(t *testing.T) {
+	p := NewMergeDuplicates(&config.Config{})
+
+	gosec := newIssueFromIssueTestCase(issueTestCase{Path: "a.go", Line: 10, Linter: "gosec", Text: "G104: Errors unhandled."})
+	errcheck := newIssueFromIssueTestCase(issueTestCase{Path: "a.go", Line: 10, Linter: "errcheck", Text: "Error return value is not checked"})
+
+	processAssertSame(t, p, gosec, errcheck)
+}
+
+func TestMergeDuplicatesDifferentIdentifiers(t *testing.T) {
+	p := NewMergeDuplicates(newMergeDuplicatesConfig())
+
+	// var a, b int
+	unusedA := newIssueFromIssueTestCase(issueTestCase{Path: "a.go", Line: 3, Linter: "unused", Text: "var `a` is unused"})
+	varcheckB := newIssueFromIssueTestCase(issueTestCase{Path: "a.go", Line: 3, Linter: "varcheck", Text: "`b` is unused"})
+
+	// a, b := f()
+	ineffassignA := newIssueFromIssueTestCase(issueTestCase{Path: "a.go", Line: 5, Linter: "ineffassign",
+		Text: "ineffectual assignment to `a`"})
+	staticcheckA := newIssueFromIssueTestCase(issueTestCase{Path: "a.go", Line: 5, Linter: "staticcheck",
+		Text: "SA4006: this value of `a` is never used"})
+	staticcheckB := newIssueFromIssueTestCase(issueTestCase{Path: "a.go", Line: 5, Linter: "staticcheck",
+		Text: "SA4006: this value of `b` is never used"})
+
+	issues := process(t, p, unusedA, varcheckB, ineffassignA, staticcheckA, staticcheckB)
+
+	ineffassignA.AlsoReportedBy = []string{"staticcheck"}
+	assert.Equal(t, []result.Issue{unusedA, varcheckB, ineffassignA, staticcheckB}, issues)
+	assert.Equal(t, "merged into the same issue from ineffassign", p.ExplainFiltered(&staticcheckA))
+}
+
+func TestMergeDuplicatesNamingIdentifiers(t *testing.T) {
+	p := NewMergeDuplicates(newMergeDuplicatesConfig())
+
+	stylecheck := newIssueFromIssueTestCase(issueTestCase{Path: "a.go", Line: 3, Linter: "stylecheck",
+		Text: "ST1003: should not use underscores in Go names; func foo_bar should be fooBar"})
+	golint := newIssueFromIssueTestCase(issueTestCase{Path: "a.go", Line: 3, Linter: "golint",
+		Text: "don't use underscores in Go names; func foo_bar should be fooBar"})
+	golintParam := newIssueFromIssueTestCase(issueTestCase{Path: "a.go", Line: 3, Linter: "golint",
+		Text: "don't use underscores in Go names; func parameter a_b should be aB"})
+
+	issues := process(t, p, stylecheck, golint, golintParam)
+
+	stylecheck.AlsoReportedBy = []string{"golint"}
+	assert.Equal(t, []result.Issue{stylecheck, golintParam}, issues)
+}
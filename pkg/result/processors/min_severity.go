@@ -0,0 +1,35 @@
+package processors
+
+import (
+	"fmt"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+// MinSeverity hides issues with severity lower than the configured one.
+type MinSeverity struct {
+	minSeverity string
+}
+
+var _ Processor = MinSeverity{}
+
+func NewMinSeverity(minSeverity string) *MinSeverity {
+	return &MinSeverity{minSeverity: minSeverity}
+}
+
+func (p MinSeverity) Process(issues []result.Issue) ([]result.Issue, error) {
+	if p.minSeverity == "" {
+		return issues, nil
+	}
+
+	return filterIssues(issues, func(i *result.Issue) bool {
+		return i.IsSeverityAtLeast(p.minSeverity)
+	}), nil
+}
+
+func (p MinSeverity) ExplainFiltered(i *result.Issue) string {
+	return fmt.Sprintf("severity %q is lower than min severity %q", i.Severity, p.minSeverity)
+}
+
+func (MinSeverity) Name() string { return "min_severity" }
+func (MinSeverity) Finish()      {}
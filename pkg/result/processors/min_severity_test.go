@@ -0,0 +1,26 @@
+package processors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/golangci/golangci-lint/pkg/result"
+)
+
+func TestMinSeverity(t *testing.T) {
+	p := NewMinSeverity("warning")
+
+	info := result.Issue{Text: "info", Severity: "info"}
+	warning := result.Issue{Text: "warning", Severity: "warning"}
+	major := result.Issue{Text: "major", Severity: "major"}
+	unknown := result.Issue{Text: "unknown", Severity: "custom"}
+	empty := result.Issue{Text: "empty"}
+
+	processedIssues := process(t, p, info, warning, major, unknown, empty)
+	assert.Equal(t, []result.Issue{warning, major, unknown, empty}, processedIssues)
+}
+
+func TestMinSeverityEmpty(t *testing.T) {
+	processAssertSame(t, NewMinSeverity(""), result.Issue{Text: "info", Severity: "info"})
+}
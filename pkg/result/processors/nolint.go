@@ -171,6 +171,28 @@ func (p *Nolint) shouldPassIssue(i *result.Issue) (bool, error) {
 	return true, nil
 }
 
+func (p *Nolint) ExplainFiltered(i *result.Issue) string {
+	if i.FromLinter == golinters.NolintlintName && i.ExpectedNoLintLinter != "" && p.enabledLinters[i.ExpectedNoLintLinter] == nil {
+		return fmt.Sprintf("linter %s isn't enabled", i.ExpectedNoLintLinter)
+	}
+
+	fd := p.cache[i.FilePath()]
+	if fd == nil {
+		return ""
+	}
+
+	for _, ir := range fd.ignoredRanges {
+		if ir.doesMatch(i) {
+			if ir.From == ir.To {
+				return fmt.Sprintf("nolint directive at line %d", ir.From)
+			}
+			return fmt.Sprintf("nolint directive for lines %d-%d", ir.From, ir.To)
+		}
+	}
+
+	return ""
+}
+
 type rangeExpander struct {
 	fset           *token.FileSet
 	inlineRanges   []ignoredRange
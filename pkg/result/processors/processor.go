@@ -9,3 +9,9 @@ type Processor interface {
 	Name() string
 	Finish()
 }
+
+// FilterExplainer is implemented by processors which can tell why they filtered out an issue.
+// It's called after processing for issues which were passed to Process but weren't returned.
+type FilterExplainer interface {
+	ExplainFiltered(i *result.Issue) string
+}
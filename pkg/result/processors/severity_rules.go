@@ -74,7 +74,10 @@ func (p SeverityRules) Process(issues []result.Issue) ([]result.Issue, error) {
 				return i
 			}
 		}
-		i.Severity = p.defaultSeverity
+		if i.Severity == "" {
+			// keep the severity reported by the linter itself
+			i.Severity = p.defaultSeverity
+		}
 		return i
 	}), nil
 }
@@ -1,6 +1,7 @@
 package processors
 
 import (
+	"go/token"
 	"path/filepath"
 	"testing"
 
@@ -104,6 +105,30 @@ func TestSeverityRulesMultiple(t *testing.T) {
 	assert.Equal(t, expectedCases, resultingCases)
 }
 
+func TestSeverityRulesKeepLinterSeverity(t *testing.T) {
+	p := NewSeverityRules("error", []SeverityRule{
+		{
+			Severity: "info",
+			BaseRule: BaseRule{
+				Linters: []string{"gosec"},
+				Path:    `_test\.go`,
+			},
+		},
+	}, nil, nil)
+
+	overridden := result.Issue{FromLinter: "gosec", Severity: "warning", Pos: token.Position{Filename: "a_test.go"}}
+	kept := result.Issue{FromLinter: "gosec", Severity: "warning", Pos: token.Position{Filename: "a.go"}}
+	defaulted := result.Issue{FromLinter: "gosec", Pos: token.Position{Filename: "b.go"}}
+
+	processedIssues := process(t, p, overridden, kept, defaulted)
+
+	var severities []string
+	for _, i := range processedIssues {
+		severities = append(severities, i.Severity)
+	}
+	assert.Equal(t, []string{"info", "warning", "error"}, severities)
+}
+
 func TestSeverityRulesText(t *testing.T) {
 	p := NewSeverityRules("", []SeverityRule{
 		{
@@ -136,6 +161,11 @@ func TestSeverityRulesEmpty(t *testing.T) {
 	processAssertSame(t, NewSeverityRules("", nil, nil, nil), newIssueFromTextTestCase("test"))
 }
 
+func TestSeverityRulesNoRulesKeepDefault(t *testing.T) {
+	// default-severity is applied only together with severity rules
+	processAssertSame(t, NewSeverityRules("error", nil, nil, nil), newIssueFromTextTestCase("test"))
+}
+
 func TestSeverityRulesCaseSensitive(t *testing.T) {
 	lineCache := fsutils.NewLineCache(fsutils.NewFileCache())
 	p := NewSeverityRulesCaseSensitive("error", []SeverityRule{
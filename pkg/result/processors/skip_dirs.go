@@ -1,6 +1,7 @@
 package processors
 
 import (
+	"fmt"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -106,34 +107,58 @@ func (p *SkipDirs) shouldPassIssue(i *result.Issue) bool {
 }
 
 func (p *SkipDirs) shouldPassIssueDirs(issueRelDir, issueAbsDir string) bool {
+	pattern := p.matchDir(issueRelDir, issueAbsDir)
+	if pattern == nil {
+		return true
+	}
+
+	if p.skippedDirs[issueRelDir] == nil {
+		p.skippedDirs[issueRelDir] = &skipStat{
+			pattern: pattern.String(),
+		}
+	}
+	p.skippedDirs[issueRelDir].count++
+	return false
+}
+
+// ShouldSkipDir reports whether files from the directory are skipped:
+// relDir is relative to the current work dir, absDir is absolute.
+// It's used to not analyze skipped packages at all.
+func (p *SkipDirs) ShouldSkipDir(relDir, absDir string) bool {
+	return p.matchDir(relDir, absDir) != nil
+}
+
+func (p *SkipDirs) matchDir(relDir, absDir string) *regexp.Regexp {
 	for _, absArgDir := range p.absArgsDirs {
-		if absArgDir == issueAbsDir {
+		if absArgDir == absDir {
 			// we must not skip issues if they are from explicitly set dirs
 			// even if they match skip patterns
-			return true
+			return nil
 		}
 	}
 
-	// We use issueRelDir for matching: it's the relative to the current
+	// We use relDir for matching: it's the relative to the current
 	// work dir path of directory of source file with the issue. It can lead
 	// to unexpected behavior if we're analyzing files out of current work dir.
 	// The alternative solution is to find relative to args path, but it has
 	// disadvantages (https://github.com/golangci/golangci-lint/pull/313).
 
 	for _, pattern := range p.patterns {
-		if pattern.MatchString(issueRelDir) {
-			ps := pattern.String()
-			if p.skippedDirs[issueRelDir] == nil {
-				p.skippedDirs[issueRelDir] = &skipStat{
-					pattern: ps,
-				}
-			}
-			p.skippedDirs[issueRelDir].count++
-			return false
+		if pattern.MatchString(relDir) {
+			return pattern
 		}
 	}
 
-	return true
+	return nil
+}
+
+func (p *SkipDirs) ExplainFiltered(i *result.Issue) string {
+	stat := p.skippedDirs[filepath.Dir(i.FilePath())]
+	if stat == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("dir matches skip-dirs pattern %q", stat.pattern)
 }
 
 func (p *SkipDirs) Finish() {
@@ -39,14 +39,30 @@ func (p SkipFiles) Process(issues []result.Issue) ([]result.Issue, error) {
 	}
 
 	return filterIssues(issues, func(i *result.Issue) bool {
-		for _, p := range p.patterns {
-			if p.MatchString(i.FilePath()) {
-				return false
-			}
+		return !p.ShouldSkipFile(i.FilePath())
+	}), nil
+}
+
+// ShouldSkipFile reports whether the file path relative to the current work dir
+// matches any of skip patterns.
+func (p SkipFiles) ShouldSkipFile(path string) bool {
+	for _, p := range p.patterns {
+		if p.MatchString(path) {
+			return true
 		}
+	}
 
-		return true
-	}), nil
+	return false
+}
+
+func (p SkipFiles) ExplainFiltered(i *result.Issue) string {
+	for _, p := range p.patterns {
+		if p.MatchString(i.FilePath()) {
+			return fmt.Sprintf("file matches skip-files pattern %q", p.String())
+		}
+	}
+
+	return ""
 }
 
 func (p SkipFiles) Finish() {}
@@ -48,3 +48,9 @@ func TestSkipFilesInvalidPattern(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, p)
 }
+
+func TestSkipFilesShouldSkipFile(t *testing.T) {
+	p := newTestSkipFiles(t, ".*\\.pb\\.go$")
+	assert.True(t, p.ShouldSkipFile("a/b.pb.go"))
+	assert.False(t, p.ShouldSkipFile("a/b.go"))
+}
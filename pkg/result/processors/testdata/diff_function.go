@@ -0,0 +1,9 @@
+package testdata
+
+func changed() {
+	a := 1
+	_ = a
+}
+
+func unchanged() {
+}
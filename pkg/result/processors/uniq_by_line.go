@@ -55,4 +55,8 @@ func (p *UniqByLine) Process(issues []result.Issue) ([]result.Issue, error) {
 	}), nil
 }
 
+func (p UniqByLine) ExplainFiltered(i *result.Issue) string {
+	return "another issue was reported on the same line"
+}
+
 func (p UniqByLine) Finish() {}
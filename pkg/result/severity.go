@@ -0,0 +1,67 @@
+package result
+
+import "strings"
+
+// Severities reported by linters which know how important their issues are.
+// Users can set any other severity names via severity rules.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// Confidences reported by linters which know how sure they are in their issues.
+const (
+	ConfidenceHigh   = "high"
+	ConfidenceMedium = "medium"
+	ConfidenceLow    = "low"
+)
+
+// SeverityScale lists known severities from the least to the most important one.
+// It includes severity names of checkstyle and code climate output formats.
+var SeverityScale = []string{
+	"ignore",
+	SeverityInfo,
+	"minor",
+	SeverityWarning,
+	"major",
+	SeverityError,
+	"critical",
+	"blocker",
+}
+
+// SeverityLevel returns the position of the severity in SeverityScale.
+// An issue without severity is treated as an error, as all printers do.
+func SeverityLevel(severity string) (int, bool) {
+	if severity == "" {
+		severity = SeverityError
+	}
+
+	for i, s := range SeverityScale {
+		if strings.EqualFold(s, severity) {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+// IsSeverityAtLeast reports whether the issue severity is not lower than the threshold.
+// Any issue passes an empty threshold. Severities unknown to SeverityScale are treated as errors.
+func (i *Issue) IsSeverityAtLeast(threshold string) bool {
+	if threshold == "" {
+		return true
+	}
+
+	thresholdLevel, ok := SeverityLevel(threshold)
+	if !ok {
+		return true
+	}
+
+	level, ok := SeverityLevel(i.Severity)
+	if !ok {
+		level, _ = SeverityLevel(SeverityError)
+	}
+
+	return level >= thresholdLevel
+}
@@ -0,0 +1,138 @@
+package timeutils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const maxInFlightTasksPerKind = 10
+
+type inFlightTask struct {
+	kind      string
+	name      string
+	startedAt time.Time
+}
+
+type namedStopwatch struct {
+	title string
+	sw    *Stopwatch
+}
+
+// InFlight tracks work in progress (linters, packages, analyzers) and stopwatches
+// of completed stages: it's dumped when the run is timed out.
+// A nil *InFlight doesn't track anything.
+type InFlight struct {
+	mu          sync.Mutex
+	nextID      int
+	tasks       map[int]inFlightTask
+	kinds       []string
+	stopwatches []namedStopwatch
+}
+
+func NewInFlight() *InFlight {
+	return &InFlight{
+		tasks: map[int]inFlightTask{},
+	}
+}
+
+// Start marks the task as in progress until the returned function is called.
+func (f *InFlight) Start(kind, name string) (done func()) {
+	if f == nil {
+		return func() {}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := f.nextID
+	f.nextID++
+	f.tasks[id] = inFlightTask{kind: kind, name: name, startedAt: time.Now()}
+
+	isKnownKind := false
+	for _, k := range f.kinds {
+		if k == kind {
+			isKnownKind = true
+			break
+		}
+	}
+	if !isKnownKind {
+		f.kinds = append(f.kinds, kind)
+	}
+
+	return func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		delete(f.tasks, id)
+	}
+}
+
+// AddStopwatch adds the stopwatch to show its slowest completed stages.
+func (f *InFlight) AddStopwatch(title string, sw *Stopwatch) {
+	if f == nil {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.stopwatches = append(f.stopwatches, namedStopwatch{title: title, sw: sw})
+}
+
+// Sprint returns the longest running in-flight tasks of every kind
+// and the slowest completed stages of every stopwatch.
+func (f *InFlight) Sprint() string {
+	if f == nil {
+		return ""
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now()
+	tasksByKind := map[string][]inFlightTask{}
+	for _, t := range f.tasks {
+		tasksByKind[t.kind] = append(tasksByKind[t.kind], t)
+	}
+
+	var b strings.Builder
+	if len(f.tasks) != 0 {
+		b.WriteString("In-flight work:\n")
+	}
+	for _, kind := range f.kinds {
+		tasks := tasksByKind[kind]
+		if len(tasks) == 0 {
+			continue
+		}
+
+		sort.Slice(tasks, func(i, j int) bool {
+			return tasks[i].startedAt.Before(tasks[j].startedAt)
+		})
+
+		var parts []string
+		for i := 0; i < len(tasks) && i < maxInFlightTasksPerKind; i++ {
+			parts = append(parts, fmt.Sprintf("%s (running %s)", tasks[i].name, now.Sub(tasks[i].startedAt).Round(time.Millisecond)))
+		}
+		if len(tasks) > maxInFlightTasksPerKind {
+			parts = append(parts, fmt.Sprintf("and %d more", len(tasks)-maxInFlightTasksPerKind))
+		}
+		fmt.Fprintf(&b, "  %s: %s\n", kind, strings.Join(parts, ", "))
+	}
+
+	var swLines []string
+	for _, nsw := range f.stopwatches {
+		nsw.sw.Lock()
+		if len(nsw.sw.stages) != 0 {
+			swLines = append(swLines, fmt.Sprintf("  %s: %s", nsw.title, nsw.sw.sprintTopStages(maxInFlightTasksPerKind)))
+		}
+		nsw.sw.Unlock()
+	}
+	if len(swLines) != 0 {
+		b.WriteString("Slowest completed stages:\n")
+		b.WriteString(strings.Join(swLines, "\n"))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,108 @@
+package timeutils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/golangci/golangci-lint/pkg/logutils"
+)
+
+// runningRe matches durations of in-flight tasks: they depend on the time of the dump.
+var runningRe = regexp.MustCompile(`\(running [^)]+\)`)
+
+func sprintInFlight(f *InFlight) string {
+	return runningRe.ReplaceAllString(f.Sprint(), "(running)")
+}
+
+// startAt starts the task and moves its start to the past: the longest running tasks go first.
+func startAt(f *InFlight, kind, name string, ago time.Duration) func() {
+	done := f.Start(kind, name)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for id, t := range f.tasks {
+		if t.kind == kind && t.name == name {
+			t.startedAt = time.Now().Add(-ago)
+			f.tasks[id] = t
+		}
+	}
+	return done
+}
+
+func TestInFlightStartDone(t *testing.T) {
+	f := NewInFlight()
+	assert.Empty(t, f.Sprint())
+
+	done := f.Start("linters", "govet")
+	doneAgain := f.Start("linters", "govet") // the same task can run twice, e.g. in isolated contexts
+	assert.Equal(t, "In-flight work:\n  linters: govet (running), govet (running)\n", sprintInFlight(f))
+
+	done()
+	assert.Equal(t, "In-flight work:\n  linters: govet (running)\n", sprintInFlight(f))
+
+	doneAgain()
+	assert.Empty(t, f.Sprint())
+}
+
+func TestInFlightOrder(t *testing.T) {
+	f := NewInFlight()
+
+	// Kinds are shown in the order of their first start, tasks from the longest running one.
+	defer startAt(f, "linters", "unused", time.Second)()
+	defer startAt(f, "linters", "govet", 3*time.Second)()
+	defer startAt(f, "packages", "a", time.Second)()
+	defer startAt(f, "analyzers", "printf", 2*time.Second)()
+	defer startAt(f, "packages", "b", 2*time.Second)()
+
+	assert.Equal(t, "In-flight work:\n"+
+		"  linters: govet (running), unused (running)\n"+
+		"  packages: b (running), a (running)\n"+
+		"  analyzers: printf (running)\n",
+		sprintInFlight(f))
+}
+
+func TestInFlightTooManyTasks(t *testing.T) {
+	f := NewInFlight()
+
+	var names []string
+	for i := 0; i < maxInFlightTasksPerKind+3; i++ {
+		name := fmt.Sprintf("p%d", i)
+		defer startAt(f, "packages", name, time.Duration(100-i)*time.Second)()
+		if i < maxInFlightTasksPerKind {
+			names = append(names, name+" (running)")
+		}
+	}
+
+	assert.Equal(t, "In-flight work:\n  packages: "+strings.Join(names, ", ")+", and 3 more\n", sprintInFlight(f))
+}
+
+func TestInFlightSlowestStages(t *testing.T) {
+	f := NewInFlight()
+
+	sw := NewStopwatch("linters", logutils.NewStderrLog(""))
+	sw.stages["govet"] = 2 * time.Second
+	sw.stages["unused"] = 3 * time.Second
+	f.AddStopwatch("linters", sw)
+	f.AddStopwatch("empty", NewStopwatch("empty", logutils.NewStderrLog(""))) // not shown
+
+	defer startAt(f, "linters", "gosec", time.Second)()
+
+	assert.Equal(t, "In-flight work:\n"+
+		"  linters: gosec (running)\n"+
+		"Slowest completed stages:\n"+
+		"  linters: top 10 stages: unused: 3s, govet: 2s\n",
+		sprintInFlight(f))
+}
+
+func TestNilInFlight(t *testing.T) {
+	var f *InFlight
+	f.Start("linters", "govet")()
+	f.AddStopwatch("linters", NewStopwatch("linters", logutils.NewStderrLog("")))
+	require.Empty(t, f.Sprint())
+}
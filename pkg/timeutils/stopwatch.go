@@ -80,6 +80,18 @@ func (s *Stopwatch) sprintTopStages(n int) string {
 	return fmt.Sprintf("top %d stages: %s", n, strings.Join(stagesStrings, ", "))
 }
 
+// Stages returns a copy of durations by stage names.
+func (s *Stopwatch) Stages() map[string]time.Duration {
+	s.Lock()
+	defer s.Unlock()
+
+	ret := make(map[string]time.Duration, len(s.stages))
+	for n, d := range s.stages {
+		ret[n] = d
+	}
+	return ret
+}
+
 func (s *Stopwatch) Print() {
 	p := fmt.Sprintf("%s took %s", s.name, time.Since(s.startedAt))
 	if len(s.stages) == 0 {
@@ -0,0 +1,113 @@
+package timeutils
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TraceEvent is a complete event of the Chrome trace event format,
+// see https://docs.google.com/document/d/1CvAClvFfyA5R-PhYUmn5OOQtYMH4h6I0nSsKchNAySU.
+type TraceEvent struct {
+	Name  string            `json:"name"`
+	Cat   string            `json:"cat,omitempty"`
+	Phase string            `json:"ph"`
+	TS    float64           `json:"ts"` // microseconds
+	Dur   float64           `json:"dur,omitempty"`
+	PID   int               `json:"pid"`
+	TID   uint64            `json:"tid"`
+	Args  map[string]string `json:"args,omitempty"`
+}
+
+// Tracer records spans of work to view them in chrome://tracing or Perfetto:
+// every goroutine gets its own lane. A nil *Tracer doesn't record anything.
+type Tracer struct {
+	startedAt time.Time
+
+	mu     sync.Mutex
+	events []TraceEvent
+}
+
+func NewTracer() *Tracer {
+	return &Tracer{
+		startedAt: time.Now(),
+	}
+}
+
+// Start starts the span of the category (e.g. "linter") in the lane
+// of the current goroutine. The span ends when the returned function is called.
+func (t *Tracer) Start(cat, name string) (end func()) {
+	if t == nil {
+		return func() {}
+	}
+
+	tid := goroutineID()
+	startedAt := time.Now()
+	return func() {
+		ev := TraceEvent{
+			Name:  name,
+			Cat:   cat,
+			Phase: "X",
+			TS:    microseconds(startedAt.Sub(t.startedAt)),
+			Dur:   microseconds(time.Since(startedAt)),
+			PID:   1,
+			TID:   tid,
+		}
+
+		t.mu.Lock()
+		t.events = append(t.events, ev)
+		t.mu.Unlock()
+	}
+}
+
+func (t *Tracer) Write(path string) error {
+	t.mu.Lock()
+	events := append([]TraceEvent{{
+		Name:  "process_name",
+		Phase: "M",
+		PID:   1,
+		Args:  map[string]string{"name": "golangci-lint"},
+	}}, t.events...)
+	t.mu.Unlock()
+
+	data, err := json.Marshal(struct {
+		TraceEvents     []TraceEvent `json:"traceEvents"`
+		DisplayTimeUnit string       `json:"displayTimeUnit"`
+	}{
+		TraceEvents:     events,
+		DisplayTimeUnit: "ms",
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal trace events")
+	}
+
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		return errors.Wrapf(err, "failed to write trace events to %s", path)
+	}
+
+	return nil
+}
+
+func microseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Microsecond)
+}
+
+// goroutineID parses the id from the "goroutine 123 [running]:" stack header:
+// the runtime doesn't expose it, but lanes by goroutines show the parallelism.
+func goroutineID() uint64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	if i := bytes.IndexByte(buf, ' '); i != -1 {
+		buf = buf[:i]
+	}
+
+	id, _ := strconv.ParseUint(string(buf), 10, 64)
+	return id
+}
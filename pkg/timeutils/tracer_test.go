@@ -0,0 +1,111 @@
+package timeutils
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readTraceEvents(t *testing.T, tr *Tracer) map[string]interface{} {
+	dir, err := ioutil.TempDir("", "golangci-lint-trace-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "trace.json")
+	require.NoError(t, tr.Write(path))
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	var trace map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &trace))
+	return trace
+}
+
+func TestTracerWrite(t *testing.T) {
+	tr := NewTracer()
+
+	endLinter := tr.Start("linter", "govet")
+	endAnalyzer := tr.Start("analyzer", "printf")
+	time.Sleep(time.Millisecond)
+	endAnalyzer()
+	time.Sleep(time.Millisecond)
+	endLinter()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		end := tr.Start("linter", "unused")
+		time.Sleep(time.Millisecond)
+		end()
+	}()
+	<-done
+
+	trace := readTraceEvents(t, tr)
+	assert.Equal(t, "ms", trace["displayTimeUnit"])
+
+	events, ok := trace["traceEvents"].([]interface{})
+	require.True(t, ok, "traceEvents must be an array")
+	require.Len(t, events, 4)
+
+	assert.Equal(t, map[string]interface{}{
+		"name": "process_name",
+		"ph":   "M",
+		"ts":   float64(0),
+		"pid":  float64(1),
+		"tid":  float64(0),
+		"args": map[string]interface{}{"name": "golangci-lint"},
+	}, events[0])
+
+	spans := map[string]map[string]interface{}{}
+	for _, e := range events[1:] {
+		ev := e.(map[string]interface{})
+		assert.Equal(t, "X", ev["ph"])
+		for _, field := range []string{"ts", "dur", "pid", "tid"} {
+			assert.IsType(t, float64(0), ev[field], "%s of %s", field, ev["name"])
+		}
+		spans[ev["name"].(string)] = ev
+	}
+	require.Len(t, spans, 3)
+
+	linter, analyzer, other := spans["govet"], spans["printf"], spans["unused"]
+	assert.Equal(t, "linter", linter["cat"])
+	assert.Equal(t, "analyzer", analyzer["cat"])
+
+	// Nested spans are in the same lane, the inner one inside the outer one.
+	assert.Equal(t, linter["tid"], analyzer["tid"])
+	assert.True(t, analyzer["ts"].(float64) >= linter["ts"].(float64))
+	assert.True(t, analyzer["ts"].(float64)+analyzer["dur"].(float64) <= linter["ts"].(float64)+linter["dur"].(float64))
+	assert.True(t, analyzer["dur"].(float64) >= float64(time.Millisecond/time.Microsecond))
+
+	// Another goroutine gets its own lane.
+	assert.NotEqual(t, linter["tid"], other["tid"])
+	assert.True(t, other["ts"].(float64) >= linter["ts"].(float64)+linter["dur"].(float64))
+}
+
+func TestTracerWriteEmpty(t *testing.T) {
+	trace := readTraceEvents(t, NewTracer())
+	assert.Len(t, trace["traceEvents"], 1) // only the process name
+}
+
+func TestNilTracer(t *testing.T) {
+	var tr *Tracer
+	end := tr.Start("linter", "govet")
+	require.NotNil(t, end)
+	end()
+}
+
+func TestGoroutineID(t *testing.T) {
+	id := goroutineID()
+	assert.NotZero(t, id)
+	assert.Equal(t, id, goroutineID())
+
+	other := make(chan uint64)
+	go func() { other <- goroutineID() }()
+	assert.NotEqual(t, id, <-other)
+}